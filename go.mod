@@ -1,3 +1,11 @@
 module obigvee.com/pomo_cli/interactiveTool/pomo
 
 go 1.20
+
+require (
+	github.com/lib/pq v1.10.9
+	github.com/mattn/go-sqlite3 v1.14.17
+	go.etcd.io/bbolt v1.3.7
+)
+
+require golang.org/x/sys v0.4.0 // indirect
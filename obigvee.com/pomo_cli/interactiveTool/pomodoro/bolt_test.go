@@ -0,0 +1,115 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro/repository"
+)
+
+func TestBoltRepo(t *testing.T) {
+	t.Run("Reopen", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pomo.db")
+
+		repo, err := repository.NewBoltRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+		intervals := []pomodoro.Interval{
+			{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+				Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Tags: []string{"a"}},
+			{StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute,
+				Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+			{StartTime: start.Add(30 * time.Minute), PlannedDuration: 25 * time.Minute,
+				Category: pomodoro.CategoryPomodoro, State: pomodoro.StateRunning},
+		}
+		if err := pomodoro.SeedRepository(repo, intervals); err != nil {
+			t.Fatal(err)
+		}
+		last := intervals[2]
+		last.ID = 3
+		last.ActualDuration = 3 * time.Minute
+		if err := repo.Update(last); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		reopened, err := repository.NewBoltRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reopened.Close()
+
+		res, err := reopened.Last()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := pomodoro.DiffInterval(last, res); len(diff) > 0 {
+			t.Errorf("Expected last interval to persist, differs in %v.\n", diff)
+		}
+		if first, err := reopened.ByID(1); err != nil {
+			t.Fatal(err)
+		} else if len(first.Tags) != 1 || first.Tags[0] != "a" {
+			t.Errorf("Expected tags %v, got %v.\n", intervals[0].Tags, first.Tags)
+		}
+		if breaks, err := reopened.Breaks(0); err != nil {
+			t.Fatal(err)
+		} else if len(breaks) != 1 || breaks[0].ID != 2 {
+			t.Errorf("Expected break with ID 2, got %v.\n", breaks)
+		}
+
+		if between, err := reopened.IntervalsBetween(start.Add(time.Minute), start.Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		} else if len(between) != 2 || between[0].ID != 2 || between[1].ID != 3 {
+			t.Errorf("Expected intervals 2 and 3, got %v.\n", between)
+		}
+
+		// the sequence of IDs persists too
+		if id, err := reopened.Create(pomodoro.Interval{}); err != nil || id != 4 {
+			t.Errorf("Expected ID 4, got %d (%v).\n", id, err)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		repo, err := repository.NewBoltRepo(filepath.Join(t.TempDir(), "pomo.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer repo.Close()
+
+		if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+		}
+		if err := repo.Update(pomodoro.Interval{ID: 1}); !errors.Is(err, pomodoro.ErrInvalidID) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidID, err)
+		}
+	})
+
+	t.Run("DeleteAll", func(t *testing.T) {
+		repo, err := repository.NewBoltRepo(filepath.Join(t.TempDir(), "pomo.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer repo.Close()
+
+		if _, err := repo.Create(pomodoro.Interval{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.DeleteAll(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+		}
+		if id, err := repo.Create(pomodoro.Interval{}); err != nil || id != 1 {
+			t.Errorf("Expected ID 1, got %d (%v).\n", id, err)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package pomodoro
+
+/**
+* This module implements the source of time used to run intervals. It can be replaced in the
+* IntervalConfig to control the passing of time, for example in tests.
+*/
+
+import (
+	"time"
+)
+
+// Clock provides the current time and the timers used to run intervals
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers ticks on its channel at regular intervals until stopped
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+// realClock implements Clock using the time package
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	*time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+func (config *IntervalConfig) clock() Clock {
+	/**
+	* clock - method returns the configured Clock, defaulting to the system clock
+	* Return: instance of Clock
+	*/
+	if config.Clock == nil {
+		return realClock{}
+	}
+
+	return config.Clock
+}
@@ -0,0 +1,262 @@
+package pomodoro_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+// fakeClock implements pomodoro.Clock with time that only moves when the test says so
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	ticks  chan time.Time
+	timers []fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+type fakeTicker struct {
+	c chan time.Time
+}
+
+func (t fakeTicker) C() <-chan time.Time { return t.c }
+func (t fakeTicker) Stop()               {}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, ticks: make(chan time.Time)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.timers = append(c.timers, fakeTimer{at: c.now.Add(d), c: ch})
+
+	return ch
+}
+
+func (c *fakeClock) NewTicker(time.Duration) pomodoro.Ticker {
+	return fakeTicker{c: c.ticks}
+}
+
+// Advance moves the clock forward, firing the timers that expire
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	pending := c.timers[:0]
+	for _, tm := range c.timers {
+		if tm.at.After(c.now) {
+			pending = append(pending, tm)
+			continue
+		}
+		tm.c <- c.now
+	}
+	c.timers = pending
+}
+
+// waitTimer blocks until a timer has been requested with After
+func (c *fakeClock) waitTimer(t *testing.T) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.mu.Lock()
+		n := len(c.timers)
+		c.mu.Unlock()
+		if n > 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("No timer was requested")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Tick delivers a tick to the running interval, then advances the clock by d.
+// It only returns once the tick is received, the interval must be ticking.
+func (c *fakeClock) Tick(t *testing.T, d time.Duration) {
+	t.Helper()
+
+	select {
+	case c.ticks <- c.Now().Add(d):
+	case <-time.After(time.Second):
+		t.Fatal("Tick was not received")
+	}
+	c.Advance(d)
+}
+
+// waitState polls the repository until the interval reaches the expected state
+func waitState(t *testing.T, repo pomodoro.Repository, id int64, state pomodoro.State) pomodoro.Interval {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		i, err := repo.ByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.State == state {
+			return i
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected state %d, got %d.\n", state, i.State)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// waitActual polls the repository until the interval has run for the expected duration
+func waitActual(t *testing.T, repo pomodoro.Repository, id int64, d time.Duration) pomodoro.Interval {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		i, err := repo.ByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.ActualDuration == d {
+			return i
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected actual duration %q, got %q.\n", d, i.ActualDuration)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestClockInjection(t *testing.T) {
+	// a fixed time far from the wall clock, results must only depend on it
+	now := time.Date(2001, time.February, 3, 10, 0, 0, 0, time.Local)
+
+	t.Run("Start", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(now)
+		config := pomodoro.NewConfig(repo, time.Second, 0, 0)
+		config.Clock = clock
+		config.MaxResumeGap = time.Hour
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errCh := make(chan error)
+		noop := func(pomodoro.Interval) {}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		if i, err = repo.ByID(i.ID); err != nil {
+			t.Fatal(err)
+		}
+		if !i.StartTime.Equal(now) {
+			t.Errorf("Expected StartTime from the clock %s, got %s.\n", now, i.StartTime)
+		}
+
+		// the resume gap is measured with the clock, not the wall clock
+		id, err := repo.Create(pomodoro.Interval{
+			StartTime:       now.Add(-10 * time.Minute),
+			PlannedDuration: time.Second,
+			Category:        pomodoro.CategoryPomodoro,
+			State:           pomodoro.StatePaused,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i, err = repo.ByID(id); err != nil {
+			t.Fatal(err)
+		}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Errorf("Expected interval within the resume gap to run, got %q.\n", err)
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		addIntervals(t, repo,
+			pomodoro.Interval{
+				StartTime:       now.Add(-2 * time.Hour),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  25 * time.Minute,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StateDone,
+			},
+			pomodoro.Interval{
+				StartTime:       now.Add(-time.Hour),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  10 * time.Minute,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StateRunning,
+			},
+		)
+		config := pomodoro.NewConfig(repo, 0, 0, 0)
+		config.DailyGoal = 2
+
+		focus, err := config.UnbrokenFocus(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if focus != 35*time.Minute {
+			t.Errorf("Expected unbroken focus %q, got %q.\n", 35*time.Minute, focus)
+		}
+
+		done, remaining, err := config.GoalProgress(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != 1 || remaining != 1 {
+			t.Errorf("Expected 1 done and 1 remaining, got %d and %d.\n", done, remaining)
+		}
+
+		eta, err := config.GoalETA(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp := now.Add(25 * time.Minute); !eta.Equal(exp) {
+			t.Errorf("Expected ETA %s, got %s.\n", exp, eta)
+		}
+
+		info, err := config.ResumePrompt(now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info == nil || info.InterruptedAgo != 50*time.Minute {
+			t.Errorf("Expected interval interrupted 50m ago, got %+v.\n", info)
+		}
+	})
+}
@@ -0,0 +1,109 @@
+package pomodoro
+
+/**
+* This module implements the JSON encoding of intervals used for export, import and IPC.
+* Durations are written as strings like "25m0s" and states by the name String gives them,
+* e.g. "Paused", read back with ParseState. The numbers written by older versions,
+* nanoseconds and State constants, are still read.
+*/
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// jsonDuration is a duration encoded as a string
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *jsonDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		// nanoseconds, as written by older versions
+		return json.Unmarshal(data, (*int64)(d))
+	}
+
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(v)
+
+	return nil
+}
+
+// jsonState is a state encoded by name
+type jsonState int
+
+func (s jsonState) MarshalJSON() ([]byte, error) {
+	if _, ok := stateStrings[State(s)]; !ok {
+		// kept as is so repositories storing JSON don't lose it, Validate reports it
+		return json.Marshal(int(s))
+	}
+
+	return json.Marshal(State(s).String())
+}
+
+func (s *jsonState) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		// the State constant, as written by older versions
+		return json.Unmarshal(data, (*int)(s))
+	}
+
+	state, err := ParseState(name)
+	if err != nil {
+		return err
+	}
+	*s = jsonState(state)
+
+	return nil
+}
+
+// intervalFields has the fields of Interval without its methods, so it's encoded as usual
+type intervalFields Interval
+
+func (i Interval) MarshalJSON() ([]byte, error) {
+	/**
+	* MarshalJSON - method encodes the interval with its durations as strings and its state
+	*				by name, an unknown state is written as a number
+	* Return: the JSON document or error
+	*/
+	return json.Marshal(struct {
+		intervalFields
+		PlannedDuration jsonDuration
+		ActualDuration  jsonDuration
+		PausedDuration  jsonDuration
+		State           jsonState
+	}{
+		intervalFields:  intervalFields(i),
+		PlannedDuration: jsonDuration(i.PlannedDuration),
+		ActualDuration:  jsonDuration(i.ActualDuration),
+		PausedDuration:  jsonDuration(i.PausedDuration),
+		State:           jsonState(i.State),
+	})
+}
+
+func (i *Interval) UnmarshalJSON(data []byte) error {
+	/**
+	* UnmarshalJSON - method decodes an interval encoded by MarshalJSON or by older versions
+	* @data: the JSON document
+	* Return: error, ErrInvalidState for an unknown state name
+	*/
+	return json.Unmarshal(data, &struct {
+		*intervalFields
+		PlannedDuration *jsonDuration
+		ActualDuration  *jsonDuration
+		PausedDuration  *jsonDuration
+		State           *jsonState
+	}{
+		intervalFields:  (*intervalFields)(i),
+		PlannedDuration: (*jsonDuration)(&i.PlannedDuration),
+		ActualDuration:  (*jsonDuration)(&i.ActualDuration),
+		PausedDuration:  (*jsonDuration)(&i.PausedDuration),
+		State:           (*jsonState)(&i.State),
+	})
+}
@@ -0,0 +1,115 @@
+package pomodoro_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestIntervalJSON(t *testing.T) {
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		interval pomodoro.Interval
+		expState string
+	}{
+		{name: "Running", expState: `"State":"Running"`,
+			interval: pomodoro.Interval{ID: 1, StartTime: start, PlannedDuration: 25 * time.Minute,
+				ActualDuration: 90 * time.Second, Category: pomodoro.CategoryPomodoro,
+				State: pomodoro.StateRunning, Tags: []string{"writing"}}},
+		{name: "Paused", expState: `"State":"Paused"`,
+			interval: pomodoro.Interval{ID: 2, StartTime: start, PlannedDuration: 5 * time.Minute,
+				ActualDuration: time.Minute, Category: pomodoro.CategoryShortBreak,
+				State: pomodoro.StatePaused, PausedDuration: 30 * time.Second,
+				PausedAt: start.Add(time.Minute), Interruptions: 1,
+				Metadata: map[string]string{"issue": "42"}}},
+		{name: "NotStarted", expState: `"State":"NotStarted"`,
+			interval: pomodoro.Interval{ID: 3, StartTime: start, PlannedDuration: 15 * time.Minute,
+				Category: pomodoro.CategoryLongBreak, State: pomodoro.StateNotStarted}},
+		{name: "PendingConfirm", expState: `"State":"PendingConfirm"`,
+			interval: pomodoro.Interval{ID: 4, StartTime: start, PlannedDuration: 25 * time.Minute,
+				ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro,
+				State: pomodoro.StatePendingConfirm}},
+	}
+
+	// Execute tests for the JSON encoding of intervals
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.interval)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			exp := []string{
+				tc.expState,
+				`"PlannedDuration":"` + tc.interval.PlannedDuration.String() + `"`,
+				`"ActualDuration":"` + tc.interval.ActualDuration.String() + `"`,
+			}
+			for _, e := range exp {
+				if !strings.Contains(string(data), e) {
+					t.Errorf("Expected %s in %s.\n", e, data)
+				}
+			}
+
+			res := pomodoro.Interval{}
+			if err := json.Unmarshal(data, &res); err != nil {
+				t.Fatal(err)
+			}
+			if diff := pomodoro.DiffInterval(tc.interval, res); len(diff) > 0 {
+				t.Errorf("Expected interval to round-trip, differs in %v.\n", diff)
+			}
+		})
+	}
+}
+
+func TestIntervalJSONLegacy(t *testing.T) {
+	// durations in nanoseconds and the State constant, as written by older versions
+	data := `{"ID":7,"PlannedDuration":1500000000000,"ActualDuration":60000000000,` +
+		`"Category":"Pomodoro","State":2}`
+
+	i := pomodoro.Interval{}
+	if err := json.Unmarshal([]byte(data), &i); err != nil {
+		t.Fatal(err)
+	}
+	if i.ID != 7 || i.PlannedDuration != 25*time.Minute || i.ActualDuration != time.Minute ||
+		i.State != pomodoro.StatePaused {
+		t.Errorf("Expected the legacy interval to decode, got %+v.\n", i)
+	}
+
+	// lowercase names with underscores are still read
+	if err := json.Unmarshal([]byte(`{"State":"pending_confirm"}`), &i); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StatePendingConfirm {
+		t.Errorf("Expected state %d, got %d.\n", pomodoro.StatePendingConfirm, i.State)
+	}
+}
+
+func TestIntervalJSONInvalid(t *testing.T) {
+	i := pomodoro.Interval{}
+	err := json.Unmarshal([]byte(`{"State":"napping"}`), &i)
+	if !errors.Is(err, pomodoro.ErrInvalidState) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidState, err)
+	}
+
+	// an unknown state round-trips as a number, for Validate to report
+	data, err := json.Marshal(pomodoro.Interval{State: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, &i); err != nil {
+		t.Fatal(err)
+	}
+	if verr := i.Validate(); i.State != -1 || !errors.Is(verr, pomodoro.ErrInvalidState) {
+		t.Errorf("Expected state -1 to round-trip, got %d.\n", i.State)
+	}
+
+	if err := json.Unmarshal([]byte(`{"ActualDuration":"soon"}`), &i); err == nil {
+		t.Error("Expected error for an invalid duration")
+	}
+}
@@ -0,0 +1,105 @@
+package pomodoro
+
+/**
+* This module implements a single stream of the lifecycle events of the intervals run by a
+* config, for reactive front-ends that prefer a channel to separate callbacks.
+*/
+
+import (
+	"sync"
+)
+
+// EventKind tells which lifecycle step an Event reports
+type EventKind int
+
+const (
+	EventCreated EventKind = iota
+	EventStarted
+	EventResumed
+	EventTick
+	EventPaused
+	EventCompleted
+	EventCancelled
+)
+
+// eventBuffer is the number of events kept for a slow subscriber before new ones are dropped
+const eventBuffer = 64
+
+// Event is a lifecycle step of an interval, with the interval as it was at that step
+type Event struct {
+	Kind     EventKind
+	Interval Interval
+}
+
+// eventStream is the channel of events of a config, created on the first subscription
+type eventStream struct {
+	mu     sync.Mutex
+	ch     chan Event
+	closed bool
+}
+
+func (config *IntervalConfig) Events() <-chan Event {
+	/**
+	* Events - method subscribes to the events of the intervals run by this config. Every
+	*		   call returns the same buffered channel, events are dropped rather than blocking
+	*		   the timer when it is full. Only configs created by NewConfig emit events.
+	* Return: the channel, closed by CloseEvents
+	*/
+	s := config.events
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ch == nil {
+		s.ch = make(chan Event, eventBuffer)
+		if s.closed {
+			close(s.ch)
+		}
+	}
+
+	return s.ch
+}
+
+func (config *IntervalConfig) CloseEvents() {
+	/**
+	* CloseEvents - method closes the channel returned by Events, no more events are emitted
+	*/
+	s := config.events
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.ch != nil {
+		close(s.ch)
+	}
+}
+
+func (config *IntervalConfig) emit(kind EventKind, i Interval) {
+	/**
+	* emit - method sends an event to the subscriber, if any, without blocking
+	* @kind: the kind of event
+	* @i: the interval the event is about
+	*/
+	s := config.events
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ch == nil || s.closed {
+		return
+	}
+	select {
+	case s.ch <- Event{Kind: kind, Interval: i}:
+	default:
+	}
+}
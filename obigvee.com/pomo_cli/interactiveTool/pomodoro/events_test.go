@@ -0,0 +1,71 @@
+package pomodoro_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestEvents(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+	config.Clock = clock
+	events := config.Events()
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, pause, resume := pomodoro.NewPausableContext(context.Background())
+	errCh := make(chan error)
+	noop := func(pomodoro.Interval) {}
+	go func() {
+		errCh <- i.Start(ctx, config, noop, noop, noop, noop)
+	}()
+
+	clock.Tick(t, time.Second)
+	pause()
+	waitState(t, repo, i.ID, pomodoro.StatePaused)
+	resume()
+	clock.Tick(t, time.Second)
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	config.CloseEvents()
+
+	expected := []pomodoro.EventKind{
+		pomodoro.EventCreated,
+		pomodoro.EventStarted,
+		pomodoro.EventTick,
+		pomodoro.EventPaused,
+		pomodoro.EventResumed,
+		pomodoro.EventTick,
+		pomodoro.EventTick,
+		pomodoro.EventCompleted,
+	}
+
+	kinds := []pomodoro.EventKind{}
+	for e := range events {
+		if e.Interval.ID != i.ID {
+			t.Errorf("Expected event of interval %d, got %d.\n", i.ID, e.Interval.ID)
+		}
+		kinds = append(kinds, e.Kind)
+	}
+
+	if len(kinds) != len(expected) {
+		t.Fatalf("Expected events %v, got %v.\n", expected, kinds)
+	}
+	for k := range expected {
+		if kinds[k] != expected[k] {
+			t.Errorf("Expected events %v, got %v.\n", expected, kinds)
+			break
+		}
+	}
+}
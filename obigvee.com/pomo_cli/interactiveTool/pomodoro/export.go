@@ -0,0 +1,61 @@
+package pomodoro
+
+/**
+* This module implements exporting the history of intervals as CSV, e.g. for spreadsheets or
+* to move it to another repository with ImportCSV.
+*/
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// columns of the CSV written by ExportCSV and read by ImportCSV, tags are joined by tagSep
+var csvHeader = []string{"id", "start_time", "category", "state", "planned_duration",
+	"actual_duration", "project", "session_tag", "task_id", "tags", "billable"}
+
+const tagSep = ";"
+
+func ExportCSV(repo Repository, w io.Writer) error {
+	/**
+	* ExportCSV - function writes every interval that is not soft-deleted as a CSV row, in
+	*			  creation order after a header row. Times are written in RFC 3339, durations
+	*			  like "25m0s" and states by name.
+	* @repo: instance of the Repository to read from
+	* @w: writer receiving the CSV data
+	* Return: error
+	*/
+	intervals, err := history(repo)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, i := range intervals {
+		record := []string{
+			strconv.FormatInt(i.ID, 10),
+			i.StartTime.Format(time.RFC3339Nano),
+			i.Category,
+			i.State.String(),
+			i.PlannedDuration.String(),
+			i.ActualDuration.String(),
+			i.Project,
+			i.SessionTag,
+			i.TaskID,
+			strings.Join(i.Tags, tagSep),
+			strconv.FormatBool(i.Billable),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+
+	return cw.Error()
+}
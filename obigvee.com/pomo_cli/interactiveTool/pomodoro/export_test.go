@@ -0,0 +1,12 @@
+package pomodoro
+
+import (
+	"os"
+)
+
+// SetNotifyInterrupt replaces the source of interrupt signals of Run, until restore is called
+func SetNotifyInterrupt(notify func(c chan<- os.Signal) func()) (restore func()) {
+	prev := notifyInterrupt
+	notifyInterrupt = notify
+	return func() { notifyInterrupt = prev }
+}
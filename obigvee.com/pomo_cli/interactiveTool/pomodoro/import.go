@@ -0,0 +1,210 @@
+package pomodoro
+
+/**
+* This module implements importing intervals tracked by other applications or exported by
+* ExportCSV into a repository, and seeding one with intervals built in Go for demos and tests.
+*/
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func ImportTogglCSV(repo Repository, r io.Reader) error {
+	/**
+	* ImportTogglCSV - function reads a detailed time entries CSV exported by Toggl and saves
+	*				   every entry as a completed pomodoro. The description of the entry is kept
+	*				   as a tag, columns other than start date, start time, duration and
+	*				   description are ignored.
+	* @repo: instance of the Repository to save the intervals to
+	* @r: reader with the CSV data, including the header row
+	* Return: error wrapping ErrInvalidImport with the line number of the first bad row
+	*/
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%w: line 1: missing header", ErrInvalidImport)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidImport, err)
+	}
+
+	cols := map[string]int{}
+	for k, name := range header {
+		cols[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = k
+	}
+	for _, name := range []string{"Start date", "Start time", "Duration"} {
+		if _, ok := cols[name]; !ok {
+			return fmt.Errorf("%w: line 1: missing column %q", ErrInvalidImport, name)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidImport, err)
+		}
+		line, _ := cr.FieldPos(0)
+
+		start, err := time.ParseInLocation("2006-01-02 15:04:05",
+			record[cols["Start date"]]+" "+record[cols["Start time"]], time.Local)
+		if err != nil {
+			return fmt.Errorf("%w: line %d: invalid start: %s", ErrInvalidImport, line, err)
+		}
+
+		d, err := parseClockDuration(record[cols["Duration"]])
+		if err != nil {
+			return fmt.Errorf("%w: line %d: invalid duration: %s", ErrInvalidImport, line, err)
+		}
+
+		i := Interval{
+			StartTime:       start,
+			PlannedDuration: d,
+			ActualDuration:  d,
+			Category:        CategoryPomodoro,
+			State:           StateDone,
+		}
+		if k, ok := cols["Description"]; ok && strings.TrimSpace(record[k]) != "" {
+			i.Tags = []string{strings.TrimSpace(record[k])}
+		}
+
+		if _, err := repo.Create(i); err != nil {
+			return err
+		}
+	}
+}
+
+func ImportCSV(repo Repository, r io.Reader) error {
+	/**
+	* ImportCSV - function reads the CSV written by ExportCSV and saves every row as a new
+	*			  interval, keeping its category, state, durations and the other exported
+	*			  fields. The IDs are assigned by the repository, the id column is ignored.
+	* @repo: instance of the Repository to save the intervals to
+	* @r: reader with the CSV data, including the header row
+	* Return: error wrapping ErrInvalidImport with the line number of the first bad row, the
+	*		  rows before it are saved
+	*/
+	cr := csv.NewReader(r)
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return fmt.Errorf("%w: line 1: missing header", ErrInvalidImport)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidImport, err)
+	}
+
+	cols := map[string]int{}
+	for k, name := range header {
+		cols[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = k
+	}
+	for _, name := range csvHeader {
+		if _, ok := cols[name]; !ok {
+			return fmt.Errorf("%w: line 1: missing column %q", ErrInvalidImport, name)
+		}
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidImport, err)
+		}
+		line, _ := cr.FieldPos(0)
+		field := func(name string) string { return record[cols[name]] }
+
+		i := Interval{
+			Category:   field("category"),
+			Project:    field("project"),
+			SessionTag: field("session_tag"),
+			TaskID:     field("task_id"),
+		}
+		if i.StartTime, err = time.Parse(time.RFC3339Nano, field("start_time")); err != nil {
+			return fmt.Errorf("%w: line %d: invalid start: %s", ErrInvalidImport, line, err)
+		}
+		if !validCategory(i.Category) {
+			return fmt.Errorf("%w: line %d: invalid category %q", ErrInvalidImport, line, i.Category)
+		}
+		if i.State, err = ParseState(field("state")); err != nil {
+			return fmt.Errorf("%w: line %d: %s", ErrInvalidImport, line, err)
+		}
+		if i.PlannedDuration, err = time.ParseDuration(field("planned_duration")); err != nil {
+			return fmt.Errorf("%w: line %d: invalid planned duration: %s", ErrInvalidImport, line, err)
+		}
+		if i.ActualDuration, err = time.ParseDuration(field("actual_duration")); err != nil {
+			return fmt.Errorf("%w: line %d: invalid actual duration: %s", ErrInvalidImport, line, err)
+		}
+		if tags := field("tags"); tags != "" {
+			i.Tags = strings.Split(tags, tagSep)
+		}
+		if i.Billable, err = strconv.ParseBool(field("billable")); err != nil {
+			return fmt.Errorf("%w: line %d: invalid billable: %s", ErrInvalidImport, line, err)
+		}
+
+		id, err := repo.Create(i)
+		if err != nil {
+			return err
+		}
+		// repositories may not store every field on creation
+		i.ID = id
+		if err := repo.Update(i); err != nil {
+			return err
+		}
+	}
+}
+
+func SeedRepository(repo Repository, intervals []Interval) error {
+	/**
+	* SeedRepository - function saves the given intervals in order, preserving every field
+	*				   but the ID which is assigned by the repository
+	* @repo: instance of the Repository to save the intervals to
+	* @intervals: the intervals to save
+	* Return: error with the position of the first interval that couldn't be saved
+	*/
+	for k, i := range intervals {
+		id, err := repo.Create(i)
+		if err != nil {
+			return fmt.Errorf("interval %d: %w", k, err)
+		}
+		// repositories may not store every field on creation
+		i.ID = id
+		if err := repo.Update(i); err != nil {
+			return fmt.Errorf("interval %d: %w", k, err)
+		}
+	}
+
+	return nil
+}
+
+func parseClockDuration(s string) (time.Duration, error) {
+	/**
+	* parseClockDuration - function parses a duration written as hours:minutes:seconds
+	* @s: the duration, for example 01:25:00
+	* Return: the duration or error if s is not in the expected format
+	*/
+	parts := strings.Split(strings.TrimSpace(s), ":")
+	if len(parts) != 3 {
+		return 0, fmt.Errorf("expected hh:mm:ss, got %q", s)
+	}
+
+	var d time.Duration
+	for k, unit := range []time.Duration{time.Hour, time.Minute, time.Second} {
+		n, err := strconv.Atoi(parts[k])
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("expected hh:mm:ss, got %q", s)
+		}
+		d += time.Duration(n) * unit
+	}
+
+	return d, nil
+}
@@ -0,0 +1,217 @@
+package pomodoro_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+const togglCSV = `User,Email,Client,Project,Task,Description,Billable,Start date,Start time,End date,End time,Duration,Tags,Amount ()
+Vee,vee@example.com,,CLI-Pomo,,Write tests,No,2023-05-10,09:00:00,2023-05-10,09:25:00,00:25:00,,
+Vee,vee@example.com,,CLI-Pomo,,,No,2023-05-10,10:00:00,2023-05-10,11:30:00,01:30:00,,
+`
+
+func TestImportTogglCSV(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	if err := pomodoro.ImportTogglCSV(repo, strings.NewReader(togglCSV)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []pomodoro.Interval{
+		{ID: 1,
+			StartTime:      time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local),
+			ActualDuration: 25 * time.Minute,
+			Tags:           []string{"Write tests"}},
+		{ID: 2,
+			StartTime:      time.Date(2023, time.May, 10, 10, 0, 0, 0, time.Local),
+			ActualDuration: 90 * time.Minute},
+	}
+
+	for _, exp := range expected {
+		i, err := repo.ByID(exp.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !i.StartTime.Equal(exp.StartTime) {
+			t.Errorf("Expected start time %s, got %s.\n", exp.StartTime, i.StartTime)
+		}
+		if i.ActualDuration != exp.ActualDuration || i.PlannedDuration != exp.ActualDuration {
+			t.Errorf("Expected durations %q, got planned %q and actual %q.\n",
+				exp.ActualDuration, i.PlannedDuration, i.ActualDuration)
+		}
+		if i.Category != pomodoro.CategoryPomodoro || i.State != pomodoro.StateDone {
+			t.Errorf("Expected completed pomodoro, got %q in state %d.\n", i.Category, i.State)
+		}
+		if strings.Join(i.Tags, ",") != strings.Join(exp.Tags, ",") {
+			t.Errorf("Expected tags %v, got %v.\n", exp.Tags, i.Tags)
+		}
+	}
+}
+
+func TestImportTogglCSVErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		data   string
+		expMsg string
+	}{
+		{name: "Empty", data: "", expMsg: "line 1"},
+		{name: "MissingColumn", data: "Description,Start date,Start time\n", expMsg: `"Duration"`},
+		{name: "BadDuration",
+			data: "Description,Start date,Start time,Duration\n" +
+				"Ok,2023-05-10,09:00:00,00:25:00\n" +
+				"Bad,2023-05-10,10:00:00,25m\n",
+			expMsg: "line 3"},
+		{name: "BadStart",
+			data: "Description,Start date,Start time,Duration\n" +
+				"Bad,10/05/2023,09:00:00,00:25:00\n",
+			expMsg: "line 2"},
+	}
+
+	// Execute tests for ImportTogglCSV errors
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			err := pomodoro.ImportTogglCSV(repo, strings.NewReader(tc.data))
+			if !errors.Is(err, pomodoro.ErrInvalidImport) {
+				t.Fatalf("Expected error %q, got %q.\n", pomodoro.ErrInvalidImport, err)
+			}
+			if !strings.Contains(err.Error(), tc.expMsg) {
+				t.Errorf("Expected error to contain %q, got %q.\n", tc.expMsg, err)
+			}
+		})
+	}
+}
+
+func TestSeedRepository(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	seed := []pomodoro.Interval{
+		{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone,
+			Tags: []string{"demo"}, Project: "CLI-Pomo"},
+		{StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute,
+			ActualDuration: 5 * time.Minute, Category: pomodoro.CategoryShortBreak,
+			State: pomodoro.StateDone},
+		{StartTime: start.Add(30 * time.Minute), PlannedDuration: 25 * time.Minute,
+			ActualDuration: 12 * time.Minute, Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StatePaused, SessionTag: "morning"},
+	}
+
+	if err := pomodoro.SeedRepository(repo, seed); err != nil {
+		t.Fatal(err)
+	}
+
+	for k, exp := range seed {
+		i, err := repo.ByID(int64(k + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		exp.ID = i.ID
+		if diff := pomodoro.DiffInterval(exp, i); len(diff) > 0 {
+			t.Errorf("Expected interval %d to be seeded as is, differs in %v.\n", k+1, diff)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	src, cleanupSrc := getRepo(t)
+	defer cleanupSrc()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.UTC)
+	seed := []pomodoro.Interval{
+		{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone,
+			Tags: []string{"writing", "review"}, Project: "CLI-Pomo", TaskID: "PROJ-42", Billable: true},
+		{StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute,
+			ActualDuration: 90 * time.Second, Category: pomodoro.CategoryShortBreak,
+			State: pomodoro.StateCancelled},
+		{StartTime: start.Add(30 * time.Minute), PlannedDuration: 25 * time.Minute,
+			ActualDuration: 12*time.Minute + 500*time.Millisecond, Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StatePaused, SessionTag: "morning, before standup"},
+		{StartTime: start.Add(time.Hour), PlannedDuration: 15 * time.Minute,
+			Category: pomodoro.CategoryLongBreak, State: pomodoro.StateNotStarted},
+	}
+	if err := pomodoro.SeedRepository(src, seed); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := pomodoro.ExportCSV(src, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, cleanupDst := getRepo(t)
+	defer cleanupDst()
+	if err := pomodoro.ImportCSV(dst, &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	exp, err := src.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := dst.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != len(exp) {
+		t.Fatalf("Expected %d intervals, got %d.\n", len(exp), len(res))
+	}
+	for k := range exp {
+		if diff := pomodoro.DiffInterval(exp[k], res[k]); len(diff) > 0 {
+			t.Errorf("Expected interval %d to round-trip, differs in %v.\n", k+1, diff)
+		}
+	}
+}
+
+func TestImportCSVErrors(t *testing.T) {
+	const header = "id,start_time,category,state,planned_duration,actual_duration," +
+		"project,session_tag,task_id,tags,billable\n"
+	const ok = "1,2023-05-10T09:00:00Z,Pomodoro,Done,25m0s,25m0s,,,,,false\n"
+
+	testCases := []struct {
+		name   string
+		data   string
+		expMsg string
+	}{
+		{name: "Empty", data: "", expMsg: "line 1"},
+		{name: "MissingColumn", data: "id,start_time,category\n", expMsg: `"state"`},
+		{name: "BadStart", data: header + ok + "2,10/05/2023,Pomodoro,Done,25m0s,25m0s,,,,,false\n",
+			expMsg: "line 3"},
+		{name: "BadCategory", data: header + "1,2023-05-10T09:00:00Z,Nap,Done,25m0s,25m0s,,,,,false\n",
+			expMsg: "line 2: invalid category"},
+		{name: "BadState", data: header + ok + ok + "3,2023-05-10T09:00:00Z,Pomodoro,Napping,25m0s,25m0s,,,,,false\n",
+			expMsg: "line 4"},
+		{name: "BadDuration", data: header + "1,2023-05-10T09:00:00Z,Pomodoro,Done,25,25m0s,,,,,false\n",
+			expMsg: "line 2: invalid planned duration"},
+		{name: "BadBillable", data: header + "1,2023-05-10T09:00:00Z,Pomodoro,Done,25m0s,25m0s,,,,,maybe\n",
+			expMsg: "line 2: invalid billable"},
+	}
+
+	// Execute tests for ImportCSV errors
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			err := pomodoro.ImportCSV(repo, strings.NewReader(tc.data))
+			if !errors.Is(err, pomodoro.ErrInvalidImport) {
+				t.Fatalf("Expected error %q, got %q.\n", pomodoro.ErrInvalidImport, err)
+			}
+			if !strings.Contains(err.Error(), tc.expMsg) {
+				t.Errorf("Expected error to contain %q, got %q.\n", tc.expMsg, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package pomodoro
+
+/**
+* This module keeps track of the intervals being ticked by an IntervalConfig, so callers can
+* find out what is running from other goroutines and pause it without racing the timer.
+*/
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// flight is an interval being ticked, with the pauser controlling its timer
+type flight struct {
+	id   int64
+	p    *pauser
+	done chan struct{} // closed when the timer stops
+	mu   sync.Mutex    // held by the timer while it saves a tick and by Pause while it saves the pause
+	// time run as counted by the timer, guarded by mu. It's ahead of the saved ActualDuration
+	// when PersistEvery is set.
+	actual time.Duration
+}
+
+// inFlight is the set of intervals currently ticked by a config, in the order they started
+type inFlight struct {
+	mu      sync.Mutex
+	flights []*flight
+}
+
+func (f *inFlight) add(id int64, p *pauser) *flight {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	fl := &flight{id: id, p: p, done: make(chan struct{})}
+	f.flights = append(f.flights, fl)
+
+	return fl
+}
+
+func (f *inFlight) remove(id int64) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for k := range f.flights {
+		if f.flights[k].id == id {
+			close(f.flights[k].done)
+			f.flights = append(f.flights[:k], f.flights[k+1:]...)
+			return
+		}
+	}
+}
+
+func (f *inFlight) find(id int64) *flight {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, fl := range f.flights {
+		if fl.id == id {
+			return fl
+		}
+	}
+
+	return nil
+}
+
+func (fl *flight) lock() {
+	if fl != nil {
+		fl.mu.Lock()
+	}
+}
+
+func (fl *flight) unlock() {
+	if fl != nil {
+		fl.mu.Unlock()
+	}
+}
+
+func (fl *flight) ran(d time.Duration) {
+	// the caller holds the lock of the flight
+	if fl != nil {
+		fl.actual = d
+	}
+}
+
+func (config *IntervalConfig) hold(i Interval) (Interval, func(), error) {
+	/**
+	* hold - method holds off the timer of an interval, if it's being ticked, and returns the
+	*		 latest version of the interval, so a change made by a caller from a stale copy
+	*		 neither overwrites a tick nor is overwritten by one. Its ActualDuration is the
+	*		 one counted by the timer, even if it isn't saved yet.
+	* @i: the interval to change
+	* Return: the interval, the function letting the timer go on once the change is saved,
+	*		  safe to call more than once, and error when the interval can't be read
+	*/
+	fl := config.inFlight.find(i.ID)
+	fl.lock()
+
+	var once sync.Once
+	release := func() { once.Do(fl.unlock) }
+
+	latest, err := config.repo.ByID(i.ID)
+	if err != nil {
+		release()
+		return i, release, err
+	}
+	if fl != nil && fl.actual > latest.ActualDuration {
+		latest.ActualDuration = fl.actual
+	}
+
+	return latest, release, nil
+}
+
+func (f *inFlight) snapshot() []*flight {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]*flight{}, f.flights...)
+}
+
+func (config *IntervalConfig) RunningIntervalID() (int64, bool) {
+	/**
+	* RunningIntervalID - method reports the interval currently being ticked by this config,
+	*					  the most recently started one if there are several. It's safe to
+	*					  call from any goroutine. Only configs created by NewConfig track it.
+	* Return: the ID of the interval, false when none is running
+	*/
+	flights := config.inFlight.snapshot()
+	if len(flights) == 0 {
+		return 0, false
+	}
+
+	return flights[len(flights)-1].id, true
+}
+
+func (config *IntervalConfig) SuspendAll() (func() error, error) {
+	/**
+	* SuspendAll - method pauses every interval being ticked by this config, e.g. on shutdown,
+	*			   and waits until the pauses are saved. The intervals stay paused within
+	*			   their Start call until the returned function resumes them.
+	* Return: function resuming the suspended intervals or error, ErrInvalidState when a
+	*		  pause is vetoed by the TransitionValidator, in which case none is suspended
+	*/
+	suspended := []*flight{}
+	for _, fl := range config.inFlight.snapshot() {
+		if fl.p.isPaused() {
+			continue // already paused by its own context
+		}
+		fl.p.pauseAndWait(fl.done)
+		suspended = append(suspended, fl)
+	}
+
+	resume := func() error {
+		for _, fl := range suspended {
+			fl.p.resume()
+		}
+		return nil
+	}
+
+	for _, fl := range suspended {
+		i, err := config.repo.ByID(fl.id)
+		if err != nil {
+			resume()
+			return nil, err
+		}
+		if i.State == StateRunning {
+			resume()
+			return nil, fmt.Errorf("%w: pausing interval %d was vetoed", ErrInvalidState, i.ID)
+		}
+	}
+
+	return resume, nil
+}
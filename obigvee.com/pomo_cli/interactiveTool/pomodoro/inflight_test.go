@@ -0,0 +1,129 @@
+package pomodoro_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestRunningIntervalID(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 2*time.Second, 0, 0)
+	config.Clock = clock
+
+	if _, ok := config.RunningIntervalID(); ok {
+		t.Fatal("Expected no running interval before starting")
+	}
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	started := make(chan struct{})
+	errCh := make(chan error)
+	noop := func(pomodoro.Interval) {}
+	go func() {
+		errCh <- i.Start(context.Background(), config,
+			func(pomodoro.Interval) { close(started) }, noop, noop, noop)
+	}()
+	<-started
+
+	// read concurrently while the interval ticks
+	var wg sync.WaitGroup
+	for k := 0; k < 4; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				config.RunningIntervalID()
+			}
+		}()
+	}
+
+	id, ok := config.RunningIntervalID()
+	if !ok || id != i.ID {
+		t.Errorf("Expected running interval %d, got %d (%t).\n", i.ID, id, ok)
+	}
+
+	clock.Tick(t, time.Second)
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if id, ok := config.RunningIntervalID(); ok {
+		t.Errorf("Expected no running interval after completion, got %d.\n", id)
+	}
+}
+
+func TestSuspendAll(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+	config.Clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const n = 3
+	ids := []int64{}
+	var wg sync.WaitGroup
+	started := make(chan struct{}, n)
+	noop := func(pomodoro.Interval) {}
+	for k := 0; k < n; k++ {
+		i, err := pomodoro.NewIntervalOfCategory(config, pomodoro.CategoryPomodoro, "", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, i.ID)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := i.Start(ctx, config,
+				func(pomodoro.Interval) { started <- struct{}{} }, noop, noop, noop); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	for k := 0; k < n; k++ {
+		<-started
+	}
+
+	resume, err := config.SuspendAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		i, err := repo.ByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.State != pomodoro.StatePaused {
+			t.Errorf("Expected interval %d to be suspended, got state %d.\n", id, i.State)
+		}
+	}
+
+	if err := resume(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range ids {
+		waitState(t, repo, id, pomodoro.StateRunning)
+	}
+
+	cancel()
+	wg.Wait()
+	for _, id := range ids {
+		waitState(t, repo, id, pomodoro.StateCancelled)
+	}
+}
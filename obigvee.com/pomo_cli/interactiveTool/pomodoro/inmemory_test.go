@@ -1,3 +1,5 @@
+//go:build !sqlite3
+
 package pomodoro_test
 
 import (
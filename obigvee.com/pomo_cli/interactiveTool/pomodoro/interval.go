@@ -2,8 +2,14 @@ package pomodoro
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
+	"math/rand"
+	"reflect"
+	"strings"
 	"time"
 )
 
@@ -14,13 +20,23 @@ const (
 	CategoryLongBreak = "LongBreak"
 )
 
+// default number of pomodoros in a cycle, the last one is followed by a long break
+const defaultPomodorosBeforeLongBreak = 4
+
+// default time between two ticks of a running interval
+const defaultTickInterval = time.Second
+
+// State is the stage of its lifecycle an interval is in
+type State int
+
 // State constants
 const (
-	StateNotStarted = iota
+	StateNotStarted State = iota
 	StateRunning
 	StatePaused
 	StateDone
 	StateCancelled
+	StatePendingConfirm // the timer expired, waiting for ConfirmComplete
 )
 
 // interval struct
@@ -31,7 +47,20 @@ type Interval struct{
 	PlannedDuration time.Duration
 	ActualDuration time.Duration
 	Category string
-	State int
+	State State
+	Tags []string
+	Project string
+	SessionTag string // label shared by all the intervals of a focus session
+	Deep bool // a completed deep pomodoro is followed by a long break
+	Deleted bool // soft-deleted intervals are kept for auditing but excluded from queries
+	TaskID string // ID of the ticket of an external task manager the interval is logged against
+	LockedBy string // owner of the process ticking the interval, when locking is enabled
+	LockHeartbeat time.Time // last time the owner of the lock showed it was alive
+	Metadata map[string]string // free-form data attached by integrations
+	Interruptions int // number of times the interval was paused while running
+	PausedDuration time.Duration // time spent paused, accumulated when the interval resumes
+	PausedAt time.Time // when the interval was last paused, zero while it isn't
+	Billable bool // the focus time of the interval can be billed to a client
 }
 
 // define Repo interface
@@ -41,6 +70,13 @@ type Repository interface{
 	ByID(id int64)(Interval, error) // retrieve an interval by ID
 	Last() (Interval, error) // find the last interval and retrieve it
 	Breaks(n int) ([]Interval, error) // retrieve a given number of interval
+	ByProject(name string) ([]Interval, error) // retrieve the intervals of a project
+	DeleteAll() error // remove every interval and reset the IDs
+	BySessionTag(tag string) ([]Interval, error) // retrieve the intervals of a tagged session
+	ByDurationRange(min, max time.Duration) ([]Interval, error) // retrieve the intervals that ran for [min, max]
+	ByTaskID(id string) ([]Interval, error) // retrieve the intervals logged against an external task
+	All() ([]Interval, error) // retrieve every interval in creation order
+	IntervalsBetween(start, end time.Time) ([]Interval, error) // retrieve the intervals started within [start, end) in chronological order
 }
 
 
@@ -53,6 +89,15 @@ var (
 	ErrIntervalCompleted = errors.New("Interval is completed or is cancelled")
 	ErrInvalidState = errors.New("Invalid State")
 	ErrInvalidID = errors.New("the ID is not valid, try another one")
+	ErrInvalidImport = errors.New("Invalid import data")
+	ErrInvalidRange = errors.New("Invalid time range")
+	ErrIntervalStale = errors.New("Interval is too old to resume")
+	ErrInvalidCategory = errors.New("Invalid category")
+	ErrIntervalLockedElsewhere = errors.New("Interval is running in another process")
+	ErrTooSoon = errors.New("Too soon to start another pomodoro")
+	ErrNothingToUndo = errors.New("Nothing to undo")
+	ErrInvalidDuration = errors.New("Invalid duration")
+	ErrCorruptedData = errors.New("Stored data is corrupted")
 )
 
 type IntervalConfig struct{
@@ -64,8 +109,91 @@ type IntervalConfig struct{
 	PomodoroDuration time.Duration
 	ShortBreakDuration time.Duration
 	LongBreakDuration time.Duration
+	BreakJitter float64 // fraction (0-1) of random variation applied to break durations
+	DailyGoal int // number of completed pomodoros aimed for each day
+	CompletionPredicate func(Interval) bool // decides whether an interval counts as a completed pomodoro
+	MaxResumeGap time.Duration // intervals started longer ago than this are not resumed, zero disables it
+	SkipLongBreaks bool // schedule short breaks in place of long breaks
+	TransitionValidator func(from, to State, i Interval) error // vetoes state transitions by returning an error
+	Clock Clock // source of time to run intervals, defaults to the system clock
+	SessionTag string // tag given to the intervals created while it is set
+	PersistEvery time.Duration // how often a running interval saves its progress, zero saves every tick
+	MinBreakRatio float64 // lowest healthy break time per focus time, zero disables the check
+	OnResume Callback // called when a paused interval runs again, unlike the start callback of Start
+	LockOwner string // identity of this process in locks, defaults to host:pid
+	LockTimeout time.Duration // age of the heartbeat after which a lock is stale, zero disables locking. Keep it above PersistEvery
+	MinGapBetweenPomodoros time.Duration // rest required between the end of a pomodoro and the start of the next
+	RequireCompletionConfirm bool // expired intervals wait in StatePendingConfirm for ConfirmComplete
+	ConfirmTimeout time.Duration // time after which a pending completion is confirmed, zero waits forever
+	CleanupNotStarted bool // starting an interval soft-deletes the other intervals that were never started
+	Location *time.Location // location of the calendar days of the stats, nil uses the location of the times given
+	PomodorosBeforeLongBreak int // number of pomodoros in a cycle, the last one is followed by a long break
+	OnCycleComplete func(pomodoros int, focus time.Duration) // called when a long break completes with the pomodoros and focus of the cycle
+	TickInterval time.Duration // time between two ticks, each tick adds it to the ActualDuration
+	Notifier Notifier // notified once each interval completes, nil disables the notifications
+	Alerter func(category string) // plays an audible cue when an interval of the category completes, run in its own goroutine
+	plugins []Plugin
+	inFlight *inFlight
+	events *eventStream
+	undo *undoStack
+}
+
+// Settings is a snapshot of the effective configuration values, for display
+type Settings struct {
+	PomodoroDuration         time.Duration
+	ShortBreakDuration       time.Duration
+	LongBreakDuration        time.Duration
+	LongBreakInterval        int
+	BreakJitter              float64
+	DailyGoal                int
+	MaxResumeGap             time.Duration
+	SkipLongBreaks           bool
+	PersistEvery             time.Duration
+	MinBreakRatio            float64
+	LockTimeout              time.Duration
+	MinGapBetweenPomodoros   time.Duration
+	RequireCompletionConfirm bool
+	ConfirmTimeout           time.Duration
+	CleanupNotStarted        bool
+	TickInterval             time.Duration
+}
+
+func (c *IntervalConfig) Settings() Settings {
+	/**
+	* Settings - method copies the effective configuration values into a Settings value
+	*			 so they can be displayed without exposing the config internals
+	* Return: instance of Settings
+	*/
+	return Settings{
+		PomodoroDuration:         c.PomodoroDuration,
+		ShortBreakDuration:       c.ShortBreakDuration,
+		LongBreakDuration:        c.LongBreakDuration,
+		LongBreakInterval:        c.pomodorosBeforeLongBreak(),
+		BreakJitter:              c.BreakJitter,
+		DailyGoal:                c.DailyGoal,
+		MaxResumeGap:             c.MaxResumeGap,
+		SkipLongBreaks:           c.SkipLongBreaks,
+		PersistEvery:             c.PersistEvery,
+		MinBreakRatio:            c.MinBreakRatio,
+		LockTimeout:              c.LockTimeout,
+		MinGapBetweenPomodoros:   c.MinGapBetweenPomodoros,
+		RequireCompletionConfirm: c.RequireCompletionConfirm,
+		ConfirmTimeout:           c.ConfirmTimeout,
+		CleanupNotStarted:        c.CleanupNotStarted,
+		TickInterval:             c.tickInterval(),
+	}
 }
 
+func (c *IntervalConfig) Fingerprint() string {
+	/**
+	* Fingerprint - method hashes the effective configuration values so clients can detect
+	*				mismatched settings, e.g. across devices
+	* Return: hex encoded SHA-256 of the Settings, identical for identical settings
+	*/
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%+v", c.Settings())))
+
+	return hex.EncodeToString(sum[:])
+}
 
 // instantiate new IntervalConfig
 func NewConfig(repo Repository, pomodoro, shortBreak, longBreak time.Duration) *IntervalConfig{
@@ -74,6 +202,14 @@ func NewConfig(repo Repository, pomodoro, shortBreak, longBreak time.Duration) *
 		PomodoroDuration: 25 * time.Minute,
 		ShortBreakDuration:  5 * time.Minute,
 		LongBreakDuration: 15 * time.Minute,
+		DailyGoal: 8,
+		PomodorosBeforeLongBreak: defaultPomodorosBeforeLongBreak,
+		TickInterval: defaultTickInterval,
+		Notifier: NopNotifier{},
+		LockOwner: defaultLockOwner(),
+		inFlight: &inFlight{},
+		events: &eventStream{},
+		undo: &undoStack{},
 	}
 	
 	if pomodoro > 0{
@@ -89,51 +225,320 @@ func NewConfig(repo Repository, pomodoro, shortBreak, longBreak time.Duration) *
 	return c
 }
 
-func nextCategory(r Repository) (string, error) {
-	li, err := r.Last()
-	if err != nil && err == ErrNoIntervals{
+func NewConfigWithActive(repo Repository, pomodoro, shortBreak, longBreak time.Duration) (*IntervalConfig, error) {
+	/**
+	* NewConfigWithActive - function instantiates an IntervalConfig like NewConfig and makes
+	*						sure the repository holds an active interval, creating the next
+	*						one (not started) when there's none, so UIs have one to display
+	* @repo: instance of the Repository
+	* @pomodoro, @shortBreak, @longBreak: durations, zero uses the defaults
+	* Return: instance of IntervalConfig or error when there's an issue accessing the repository
+	*/
+	c := NewConfig(repo, pomodoro, shortBreak, longBreak)
+
+	if _, err := GetInterVal(c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func NewConfigValidated(repo Repository, pomodoro, shortBreak, longBreak time.Duration) (*IntervalConfig, error) {
+	/**
+	* NewConfigValidated - function instantiates an IntervalConfig like NewConfig, rejecting
+	*					   negative durations instead of silently using the defaults
+	* @repo: instance of the Repository
+	* @pomodoro, @shortBreak, @longBreak: durations, zero uses the defaults
+	* Return: instance of IntervalConfig or ErrInvalidDuration when a duration is negative
+	*/
+	durations := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"pomodoro", pomodoro}, {"short break", shortBreak}, {"long break", longBreak},
+	}
+	for _, d := range durations {
+		if d.d < 0 {
+			return nil, fmt.Errorf("%w: %s of %s", ErrInvalidDuration, d.name, d.d)
+		}
+	}
+
+	return NewConfig(repo, pomodoro, shortBreak, longBreak), nil
+}
+
+func (config *IntervalConfig) completed(i Interval) bool {
+	/**
+	* completed - method decides whether an interval counts as a completed pomodoro, using
+	*			  the configured CompletionPredicate when set
+	* @i: the interval to check
+	* Return: true if the interval counts as completed
+	*/
+	if config.CompletionPredicate != nil {
+		return config.CompletionPredicate(i)
+	}
+
+	return i.State == StateDone && i.Category == CategoryPomodoro
+}
+
+func (config *IntervalConfig) validateTransition(from, to State, i Interval) error {
+	/**
+	* validateTransition - method consults the TransitionValidator, if any, before an interval
+	*					   changes state
+	* @from: current state of the interval
+	* @to: state the interval is about to change to
+	* @i: the interval
+	* Return: error from the validator aborting the transition, nil when it's allowed
+	*/
+	if config.TransitionValidator == nil {
+		return nil
+	}
+
+	return config.TransitionValidator(from, to, i)
+}
+
+func (config *IntervalConfig) checkGap(i Interval) error {
+	/**
+	* checkGap - method makes sure MinGapBetweenPomodoros has passed since the last pomodoro
+	*			 ended before starting a new one
+	* @i: the interval about to start
+	* Return: error wrapping ErrTooSoon with the remaining wait
+	*/
+	if config.MinGapBetweenPomodoros <= 0 || i.Category != CategoryPomodoro {
+		return nil
+	}
+
+	intervals, err := history(config.repo)
+	if err != nil {
+		return err
+	}
+
+	for k := len(intervals) - 1; k >= 0; k-- {
+		last := intervals[k]
+		if last.ID == i.ID || last.Category != CategoryPomodoro || last.ActualDuration == 0 {
+			continue
+		}
+		if last.State != StateDone && last.State != StateCancelled {
+			continue
+		}
+
+		_, end := focusSpan(last)
+		if wait := config.MinGapBetweenPomodoros - config.clock().Now().Sub(end); wait > 0 {
+			return fmt.Errorf("%w: wait %s", ErrTooSoon, wait.Round(time.Second))
+		}
+		return nil
+	}
+
+	return nil
+}
+
+func nextCategory(config *IntervalConfig) (string, error) {
+	category, err := cycleCategory(config)
+	if err != nil {
+		return "", err
+	}
+
+	// a break is only due after a pomodoro, a deep one earns a long break
+	if category != CategoryPomodoro {
+		li, err := config.repo.Last()
+		if err != nil {
+			return "", err
+		}
+		if li.Deep && li.State == StateDone {
+			category = CategoryLongBreak
+		}
+	}
+
+	// the cycle is still tracked so long breaks resume when the option is disabled
+	if category == CategoryLongBreak && config.SkipLongBreaks {
+		return CategoryShortBreak, nil
+	}
+
+	return category, nil
+}
+
+func (config *IntervalConfig) pomodorosBeforeLongBreak() int {
+	/**
+	* pomodorosBeforeLongBreak - method returns the number of pomodoros in a cycle, the
+	*							 default one when PomodorosBeforeLongBreak isn't set
+	* Return: the number of pomodoros
+	*/
+	if config.PomodorosBeforeLongBreak <= 0 {
+		return defaultPomodorosBeforeLongBreak
+	}
+
+	return config.PomodorosBeforeLongBreak
+}
+
+func (config *IntervalConfig) tickInterval() time.Duration {
+	/**
+	* tickInterval - method returns the time between two ticks, the default one when
+	*				 TickInterval isn't set
+	* Return: the time between two ticks
+	*/
+	if config.TickInterval <= 0 {
+		return defaultTickInterval
+	}
+
+	return config.TickInterval
+}
+
+func cycleCategory(config *IntervalConfig) (string, error) {
+	/**
+	* cycleCategory - function decides the category following the last interval. A pomodoro
+	*				  follows any break. A break follows a pomodoro: a long one once N
+	*				  pomodoros were completed since the last long break, N being
+	*				  PomodorosBeforeLongBreak, a short one otherwise. With the default of 4
+	*				  the cadence is P S P S P S P L. Pomodoros that didn't complete don't
+	*				  count and skipped breaks don't shift the cycle, a long break replaced
+	*				  by SkipLongBreaks is still due once the option is disabled.
+	* @config: instance of IntervalConfig
+	* Return: the category or error when there's an issue accessing the repository
+	*/
+	li, err := config.repo.Last()
+	if err == ErrNoIntervals {
 		return CategoryPomodoro, nil
 	}
-	if err != nil{
+	if err != nil {
 		return "", err
 	}
-	if li.Category == CategoryLongBreak || li.Category == CategoryShortBreak{
+	if li.Category == CategoryLongBreak || li.Category == CategoryShortBreak {
 		return CategoryPomodoro, nil
 	}
-	lastBreaks, err := r.Breaks(3)
-	if err != nil{
+
+	done, err := config.pomodorosSinceLongBreak()
+	if err != nil {
 		return "", err
 	}
-	if len(lastBreaks) < 3{
+	if done >= config.pomodorosBeforeLongBreak() {
 		return CategoryLongBreak, nil
 	}
 
-	for _, i := range lastBreaks{
-		if i.Category == CategoryLongBreak{
-			return CategoryShortBreak, nil
+	return CategoryShortBreak, nil
+}
+
+func (config *IntervalConfig) pomodorosSinceLongBreak() (int, error) {
+	/**
+	* pomodorosSinceLongBreak - method counts the pomodoros completed since the last long break
+	* Return: the number of pomodoros or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return 0, err
+	}
+
+	done := 0
+	for k := len(intervals) - 1; k >= 0; k-- {
+		i := intervals[k]
+		if i.Category == CategoryLongBreak {
+			break
+		}
+		if i.Category == CategoryPomodoro && config.completed(i) {
+			done++
 		}
 	}
-	
-	return CategoryLongBreak, nil
+
+	return done, nil
+}
+
+// Plugin runs user code after an interval completes
+type Plugin interface {
+	OnComplete(i Interval) error
+}
+
+func (config *IntervalConfig) RegisterPlugin(p Plugin) {
+	/**
+	* RegisterPlugin - method adds a plugin to run after each interval reaches StateDone.
+	*				   Plugins must be registered before starting intervals.
+	* @p: the plugin
+	*/
+	config.plugins = append(config.plugins, p)
+}
+
+func (config *IntervalConfig) afterDone(i Interval) {
+	/**
+	* afterDone - method runs the registered plugins and the Notifier for a completed interval,
+	*			  logging their errors so they never abort the timer, starts the Alerter and
+	*			  reports the end of a cycle to OnCycleComplete when a long break completes
+	* @i: the completed interval
+	*/
+	for _, p := range config.plugins {
+		if err := p.OnComplete(i); err != nil {
+			log.Printf("pomodoro: plugin failed for interval %d: %s", i.ID, err)
+		}
+	}
+	if config.Notifier != nil {
+		if err := config.Notifier.Notify(i); err != nil {
+			log.Printf("pomodoro: notification failed for interval %d: %s", i.ID, err)
+		}
+	}
+	if config.Alerter != nil {
+		go config.Alerter(i.Category) // a sound playing must not hold the timer
+	}
+
+	if i.Category != CategoryLongBreak || config.OnCycleComplete == nil {
+		return
+	}
+	pomodoros, focus, err := config.cycleSummary(i)
+	if err != nil {
+		log.Printf("pomodoro: cycle summary failed for interval %d: %s", i.ID, err)
+		return
+	}
+	config.OnCycleComplete(pomodoros, focus)
+}
+
+func (config *IntervalConfig) unpause(i *Interval) {
+	/**
+	* unpause - method adds the time since the interval was paused to its PausedDuration
+	* @i: the interval being resumed
+	*/
+	if i.PausedAt.IsZero() {
+		return
+	}
+	i.PausedDuration += config.clock().Now().Sub(i.PausedAt)
+	i.PausedAt = time.Time{}
+}
+
+func (config *IntervalConfig) resumed(i Interval) {
+	/**
+	* resumed - method emits EventResumed and calls the OnResume callback, if any, for an
+	*			interval running again
+	* @i: the resumed interval
+	*/
+	config.emit(EventResumed, i)
+	if config.OnResume != nil {
+		config.OnResume(i)
+	}
 }
 
 // Callback function accepts an instance of type interval as input return nothing
 type Callback func(Interval)
 
 func tick(ctx context.Context, id int64, config *IntervalConfig,
-		start, periodic, end Callback) error {
+		start, periodic, end, paused Callback) error {
 			/**
 			* tick - function  controls the timer for each interval's execution.
-			* @ctx: instance of context.Context, it indicates a cancellation
+			* @ctx: instance of context.Context, it indicates a cancellation and carries the
+			*		pause requests of a context created by NewPausableContext
 			* @id: id of interval to control
 			* @config: instance of the configuration IntervalConfig
 			* @start: Callback function
 			* @periodic: Callback function
 			* @end: Callback function
+			* @paused: Callback function, called when the interval is paused, tick returns
+			*		   afterwards unless the pause came through the context or the in-flight set
 			* Return : error
 			*/
 
-		ticker := time.NewTicker(time.Second)
+		p := pauserFrom(ctx)
+		if p == nil {
+			p = newPauser() // pause requests can still come through the in-flight set
+		}
+		fl := config.inFlight.add(id, p)
+		defer config.inFlight.remove(id)
+
+		clock := config.clock()
+		step := config.tickInterval()
+		ticker := clock.NewTicker(step)
 		defer ticker.Stop()
 		
 		i, err := config.repo.ByID(id)
@@ -141,42 +546,445 @@ func tick(ctx context.Context, id int64, config *IntervalConfig,
 			return err
 		}
 
-		expire := time.After(i.PlannedDuration - i.ActualDuration)
+		expire := clock.After(i.PlannedDuration - i.ActualDuration)
+		planned := i.PlannedDuration
+		// the running duration is kept here and only saved every PersistEvery,
+		// it is flushed whenever the interval stops running
+		actual, saved := i.ActualDuration, i.ActualDuration
+		fl.lock()
+		fl.ran(actual)
+		fl.unlock()
 		start(i)
 
+		// saves the time run so far to an interval a caller stopped meanwhile
+		stopped := func(i Interval) error {
+			if unlock(&i) || actual != saved {
+				return config.repo.Update(i)
+			}
+			return nil
+		}
+
+		// reads the interval and saves the tick holding the lock of the flight, so a Pause
+		// made in between is never overwritten with the running state
+		advance := func() (Interval, error) {
+			fl.lock()
+			defer fl.unlock()
+
+			i, err := config.repo.ByID(id)
+			if err != nil{
+				return i, err
+			}
+			i.ActualDuration = actual
+			if !i.IsValidState() {
+				return i, fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+			}
+			if i.State != StateRunning{
+				return i, stopped(i)
+			}
+			// the interval may have been converted to another category meanwhile
+			if i.PlannedDuration != planned {
+				planned = i.PlannedDuration
+				expire = clock.After(i.PlannedDuration - i.ActualDuration)
+			}
+
+			actual += step
+			fl.ran(actual)
+			i.ActualDuration = actual
+			if actual-saved >= config.PersistEvery {
+				config.lock(&i) // the heartbeat
+				if err := config.repo.Update(i); err != nil{
+					return i, err
+				}
+				saved = actual
+			}
+			return i, nil
+		}
+
+		// ends the interval once its time is up, reports false when a caller stopped it meanwhile
+		expired := func() (Interval, bool, error) {
+			fl.lock()
+			defer fl.unlock()
+
+			i, err := config.repo.ByID(id)
+			if err != nil {
+				return i, false, err
+			}
+			i.ActualDuration = actual
+			if i.State != StateRunning {
+				return i, false, stopped(i)
+			}
+			unlock(&i)
+			i.State = StateDone
+			if config.RequireCompletionConfirm {
+				i.State = StatePendingConfirm
+			}
+
+			return i, true, config.repo.Update(i)
+		}
+
+		// cancels the interval along with the context, reports false when a caller already
+		// completed or cancelled it
+		cancelled := func() (Interval, bool, error) {
+			fl.lock()
+			defer fl.unlock()
+
+			i, err := config.repo.ByID(id)
+			if err != nil {
+				return i, false, err
+			}
+			i.ActualDuration = actual
+			if i.State == StateDone || i.State == StateCancelled {
+				return i, false, stopped(i)
+			}
+			i.State = StateCancelled
+			unlock(&i)
+
+			return i, true, config.repo.Update(i)
+		}
+
+		// pauses the interval on a request of the context, reports false when the pause was
+		// vetoed or a caller stopped the interval meanwhile
+		pause := func() (Interval, bool, error) {
+			fl.lock()
+			defer fl.unlock()
+
+			i, err := config.repo.ByID(id)
+			if err != nil {
+				return i, false, err
+			}
+			i.ActualDuration = actual
+			if i.State != StateRunning {
+				return i, false, stopped(i)
+			}
+			if err := config.validateTransition(i.State, StatePaused, i); err != nil {
+				return i, false, nil
+			}
+			i.State = StatePaused
+			i.Interruptions++
+			i.PausedAt = clock.Now()
+			unlock(&i)
+			if err := config.repo.Update(i); err != nil {
+				return i, false, err
+			}
+			saved = actual
+
+			return i, true, nil
+		}
+
+		// resumes the interval paused through the context, reports false when a caller
+		// completed or cancelled it meanwhile
+		resume := func() (Interval, bool, error) {
+			fl.lock()
+			defer fl.unlock()
+
+			i, err := config.repo.ByID(id)
+			if err != nil {
+				return i, false, err
+			}
+			if i.State != StatePaused {
+				return i, false, nil
+			}
+			// the interval may have been converted to another category meanwhile
+			expire = clock.After(i.PlannedDuration - i.ActualDuration)
+			planned = i.PlannedDuration
+			actual, saved = i.ActualDuration, i.ActualDuration
+			fl.ran(actual)
+			i.State = StateRunning
+			config.unpause(&i)
+			config.lock(&i)
+
+			return i, true, config.repo.Update(i)
+		}
+
 		for{
 			select {
-			case <-ticker.C:
-				i, err := config.repo.ByID(id)
+			case <-ticker.C():
+				i, err := advance()
 				if err != nil{
 					return err
 				}
-				if i.State == StatePaused{
+				if i.State != StateRunning{
+					if i.State == StatePaused {
+						paused(i)
+					}
 					return nil
 				}
-				
-				i.ActualDuration += time.Second
-				if err := config.repo.Update(i); err != nil{
-					return err
-				}
+				config.emit(EventTick, i)
 				periodic(i)
 			case <-expire:
-				i, err := config.repo.ByID(id)
+				i, running, err := expired()
 				if err != nil {
 					return err
 				}
-				i.State = StateDone
+				if !running {
+					if i.State == StatePaused {
+						paused(i)
+					}
+					return nil
+				}
+				if i.State == StatePendingConfirm {
+					return config.awaitConfirm(ctx, id, ticker, end)
+				}
 				end(i)
-				return config.repo.Update(i)
+				config.emit(EventCompleted, i)
+				config.afterDone(i)
+				return nil
 			case <-ctx.Done():
-				i, err := config.repo.ByID(id)
+				i, running, err := cancelled()
 				if err != nil{
 					return err
 				}
-				i.State = StateCancelled
-				return config.repo.Update(i)
+				if running {
+					config.emit(EventCancelled, i)
+				}
+				return nil
+			case <-p.changed():
+				if !p.isPaused() {
+					continue
+				}
+				i, pausedNow, err := pause()
+				p.acknowledge()
+				if err != nil {
+					return err
+				}
+				if !pausedNow {
+					if i.State == StateRunning {
+						continue // the pause was vetoed, keep running
+					}
+					if i.State == StatePaused {
+						paused(i)
+					}
+					return nil
+				}
+				config.emit(EventPaused, i)
+				paused(i)
+
+				for p.isPaused() {
+					select {
+					case <-p.changed():
+					case <-ticker.C():
+						// a caller may complete or cancel the interval while it's paused
+						i, err := config.repo.ByID(id)
+						if err != nil {
+							return err
+						}
+						if i.State != StatePaused {
+							return nil
+						}
+					case <-ctx.Done():
+						i, running, err := cancelled()
+						if err != nil {
+							return err
+						}
+						if running {
+							config.emit(EventCancelled, i)
+						}
+						return nil
+					}
+				}
+
+				i, resumedNow, err := resume()
+				if err != nil {
+					return err
+				}
+				if !resumedNow {
+					return nil // completed or cancelled meanwhile
+				}
+				config.resumed(i)
+			}
+		}
+}
+
+func (config *IntervalConfig) awaitConfirm(ctx context.Context, id int64, ticker Ticker,
+	end Callback) error {
+	/**
+	* awaitConfirm - method waits for the completion of an expired interval to be confirmed
+	*				 by ConfirmComplete, confirming it itself after ConfirmTimeout
+	* @ctx: instance of context.Context, cancelling it stops waiting but the interval can
+	*		still be confirmed
+	* @id: id of the interval pending confirmation
+	* @ticker: the ticker of the interval, used to check for the confirmation
+	* @end: Callback function, called once the interval is done
+	* Return: error
+	*/
+	var timeout <-chan time.Time
+	if config.ConfirmTimeout > 0 {
+		timeout = config.clock().After(config.ConfirmTimeout)
+	}
+
+	for {
+		select {
+		case <-ticker.C():
+		case <-timeout:
+			i, err := config.repo.ByID(id)
+			if err != nil {
+				return err
 			}
+			if i.State == StatePendingConfirm {
+				end(i)
+				return config.complete(i)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+
+		i, err := config.repo.ByID(id)
+		if err != nil {
+			return err
+		}
+		if i.State == StatePendingConfirm {
+			continue
+		}
+		if i.State == StateDone {
+			end(i)
 		}
+		return nil
+	}
+}
+
+func (config *IntervalConfig) complete(i Interval) error {
+	/**
+	* complete - method marks an interval as done, saves it and notifies the subscribers
+	*			 and plugins
+	* @i: the interval
+	* Return: error
+	*/
+	i.State = StateDone
+	if err := config.update(i); err != nil {
+		return err
+	}
+	config.emit(EventCompleted, i)
+	config.afterDone(i)
+
+	return nil
+}
+
+func (i Interval) ConfirmComplete(config *IntervalConfig) error {
+	/**
+	* ConfirmComplete - method acknowledges the completion of an interval whose timer expired
+	*					while RequireCompletionConfirm is set, marking it as done
+	* @config: instance of IntervalConfig
+	* Return: error, ErrInvalidState when the interval isn't pending confirmation
+	*/
+	if i.State != StatePendingConfirm {
+		return fmt.Errorf("%w: %d is not pending confirmation", ErrInvalidState, i.State)
+	}
+
+	if err := config.validateTransition(i.State, StateDone, i); err != nil {
+		return err
+	}
+
+	return config.complete(i)
+}
+
+func validCategory(category string) bool {
+	/**
+	* validCategory - function checks a category is one of the known ones
+	* @category: the category to check
+	* Return: true if the category is known
+	*/
+	switch category {
+	case CategoryPomodoro, CategoryShortBreak, CategoryLongBreak:
+		return true
+	default:
+		return false
+	}
+}
+
+func (i Interval) IsValidState() bool {
+	/**
+	* IsValidState - method checks the state of the interval is one of the State constants
+	* Return: true if the state is known
+	*/
+	switch i.State {
+	case StateNotStarted, StateRunning, StatePaused, StateDone, StateCancelled, StatePendingConfirm:
+		return true
+	default:
+		return false
+	}
+}
+
+func (i Interval) Validate() error {
+	/**
+	* Validate - method checks the interval holds values the package knows how to handle,
+	*			 e.g. after reading it from a file or another backend
+	* Return: error, ErrInvalidState for an unknown state or ErrInvalidCategory for an
+	*		  unknown category
+	*/
+	if !i.IsValidState() {
+		return fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+	}
+	if !validCategory(i.Category) {
+		return fmt.Errorf("%w: %q", ErrInvalidCategory, i.Category)
+	}
+
+	return nil
+}
+
+func (i Interval) Remaining() time.Duration {
+	/**
+	* Remaining - method computes the time left before the interval expires
+	* Return: PlannedDuration minus ActualDuration, zero once the interval ran its planned duration
+	*/
+	if i.ActualDuration >= i.PlannedDuration {
+		return 0
+	}
+
+	return i.PlannedDuration - i.ActualDuration
+}
+
+func (i Interval) Progress() float64 {
+	/**
+	* Progress - method computes the fraction of the planned duration elapsed, e.g. for
+	*			 progress bars
+	* Return: value from 0.0 to 1.0, 1.0 when there's no planned duration
+	*/
+	if i.PlannedDuration <= 0 {
+		return 1
+	}
+
+	return 1 - float64(i.Remaining())/float64(i.PlannedDuration)
+}
+
+// names of the states, by State constant
+var stateStrings = map[State]string{
+	StateNotStarted:     "NotStarted",
+	StateRunning:        "Running",
+	StatePaused:         "Paused",
+	StateDone:           "Done",
+	StateCancelled:      "Cancelled",
+	StatePendingConfirm: "PendingConfirm",
+}
+
+func (s State) String() string {
+	/**
+	* String - method names the state, e.g. for logs
+	* Return: the name of the State constant without its prefix, e.g. "Paused", or the number
+	*		  of an unknown state, e.g. "State(7)"
+	*/
+	if name, ok := stateStrings[s]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("State(%d)", int(s))
+}
+
+func ParseState(name string) (State, error) {
+	/**
+	* ParseState - function finds the state named by String, ignoring the case and the
+	*			   underscores or spaces between words, e.g. "pending_confirm"
+	* @name: the name of the state
+	* Return: the state or ErrInvalidState when no state has the name
+	*/
+	normalize := strings.NewReplacer("_", "", " ", "", "-", "")
+	key := strings.ToLower(normalize.Replace(name))
+	for s, n := range stateStrings {
+		if strings.ToLower(n) == key {
+			return s, nil
+		}
+	}
+
+	return 0, fmt.Errorf("%w: %q", ErrInvalidState, name)
 }
 
 func newInterval(config *IntervalConfig) (Interval, error) {
@@ -186,30 +994,89 @@ func newInterval(config *IntervalConfig) (Interval, error) {
 * 
 * Returns: a interval instance with appropriate category and values
 */
-	i := Interval{}
-	category, err := nextCategory(config.repo)
+	category, err := nextCategory(config)
 	if err != nil {
-		return i, err
+		return Interval{}, err
 	}
 
-	i.Category = category
-	
-	switch category {
-	case CategoryPomodoro:
-		i.PlannedDuration = config.PomodoroDuration
-	case CategoryShortBreak:
-		i.PlannedDuration = config.ShortBreakDuration
-	case CategoryLongBreak:
-		i.PlannedDuration = config.LongBreakDuration
+	return NewIntervalOfCategory(config, category, "", false)
+}
+
+func NewIntervalOfCategory(config *IntervalConfig, category, taskID string, billable bool) (Interval, error) {
+	/**
+	* NewIntervalOfCategory - function saves a new interval, not started, of the given category
+	*						  regardless of the pomodoro cycle, e.g. to log work on a task
+	* @config: an instance of the intervalConfig
+	* @category: one of CategoryPomodoro, CategoryShortBreak or CategoryLongBreak
+	* @taskID: optional ID of the external task the interval is logged against
+	* @billable: whether the focus time of the interval can be billed to a client
+	* Return: the new interval or error, ErrInvalidCategory for an unknown category
+	*/
+	i := Interval{}
+	if !validCategory(category) {
+		return i, fmt.Errorf("%w: %q", ErrInvalidCategory, category)
 	}
 
+	var err error
+	i.Category = category
+	i.TaskID = taskID
+	i.Billable = billable
+	i.SessionTag = config.SessionTag
+	i.PlannedDuration = config.plannedDuration(i)
+
 	if i.ID, err = config.repo.Create(i); err != nil{
 		return i, err
 	}
+
+	// the jitter of breaks is seeded by the ID, only known once the interval is saved
+	if d := config.plannedDuration(i); d != i.PlannedDuration {
+		i.PlannedDuration = d
+		if err := config.repo.Update(i); err != nil {
+			return i, err
+		}
+	}
+	config.emit(EventCreated, i)
 	
 	return i, nil
 }
 
+func (config *IntervalConfig) plannedDuration(i Interval) time.Duration {
+	/**
+	* plannedDuration - method returns the duration configured for the category of an interval,
+	*					varied by BreakJitter for breaks that already have an ID
+	* @i: the interval
+	* Return: the planned duration
+	*/
+	switch i.Category {
+	case CategoryShortBreak:
+		return jitter(config.ShortBreakDuration, config.BreakJitter, i.ID)
+	case CategoryLongBreak:
+		return jitter(config.LongBreakDuration, config.BreakJitter, i.ID)
+	default:
+		return config.PomodoroDuration
+	}
+}
+
+func jitter(d time.Duration, amount float64, id int64) time.Duration {
+	/**
+	* jitter - function randomly stretches or shrinks a duration by up to the given fraction.
+	*		   The variation is seeded by the interval ID so it is reproducible.
+	* @d: duration to vary
+	* @amount: maximum fraction of variation, clamped to the range 0-1
+	* @id: ID of the interval the duration belongs to
+	* Return: the varied duration, or d unchanged when amount or id is zero
+	*/
+	if amount <= 0 || id == 0 {
+		return d
+	}
+	if amount > 1 {
+		amount = 1
+	}
+
+	r := rand.New(rand.NewSource(id))
+	return time.Duration(float64(d) * (1 + amount*(2*r.Float64()-1)))
+}
+
 func GetInterVal(config *IntervalConfig) (Interval, error)  {
 	/**
 	* GetInterval - attempts to retrieve the last interval from the repository 
@@ -236,8 +1103,34 @@ func GetInterVal(config *IntervalConfig) (Interval, error)  {
 	return newInterval(config)
 }
 
+func NextIntervalWithNotice(config *IntervalConfig, onNew func(Interval)) (Interval, error) {
+	/**
+	* NextIntervalWithNotice - function works as GetInterVal and notifies the caller when a
+	*						   brand-new interval is created, e.g. to show the change of category
+	* @config: instance of IntervalConfig
+	* @onNew: function called with the new interval, not called when the last interval is
+	*		  resumed. It can be nil
+	* Return: Interval instance or error when there's an issue accessing the repository
+	*/
+	last, err := config.repo.Last()
+	if err != nil && err != ErrNoIntervals {
+		return Interval{}, err
+	}
+
+	i, err := GetInterVal(config)
+	if err != nil {
+		return i, err
+	}
+
+	if i.ID != last.ID && onNew != nil {
+		onNew(i)
+	}
+
+	return i, nil
+}
+
 func (i Interval) Start(ctx context.Context, config *IntervalConfig,
-	start, periodic, end Callback) error {
+	start, periodic, end, paused Callback) error {
 	/**
 	* Start() - method is used by callers to start the interval timer.
 				It, checks the state of the current interval setting the appropriate options
@@ -247,22 +1140,79 @@ func (i Interval) Start(ctx context.Context, config *IntervalConfig,
 	* @ctx: instance of context.Context
 	* @config:instance of IntervalConfig
 	* @ start, @periodic @ end : Callback function
-	* Return: error
+	* @paused: Callback function, called when the interval is paused while it runs so the
+			   caller can tell a pause from a completion or a cancellation and call Start
+			   again to resume
+	* Return: error, ErrTooSoon when a pomodoro is started less than MinGapBetweenPomodoros
+			  after the previous one ended, ErrIntervalStale when a running or paused interval started longer than
+			  MaxResumeGap ago, in which case it is cancelled so the caller can start a fresh one,
+			  ErrIntervalLockedElsewhere when another live process holds the lock of the interval.
+			  A running interval with a stale lock is taken over. With CleanupNotStarted, starting
+			  an interval soft-deletes the other intervals that were never started.
 	*/
+	if (i.State == StateRunning || i.State == StatePaused) && config.MaxResumeGap > 0 {
+		if gap := config.clock().Now().Sub(i.StartTime); gap > config.MaxResumeGap {
+			if err := config.validateTransition(i.State, StateCancelled, i); err != nil {
+				return err
+			}
+			i.State = StateCancelled
+			if err := config.repo.Update(i); err != nil {
+				return err
+			}
+			config.emit(EventCancelled, i)
+			return fmt.Errorf("%w: started %s ago", ErrIntervalStale, gap.Round(time.Second))
+		}
+	}
+
+	stale, err := config.checkLock(i)
+	if err != nil {
+		return err
+	}
+
+	from := i.State
 	switch i.State {
 	case StateRunning:
-		return nil
+		if !stale {
+			return nil
+		}
+		config.lock(&i)
+		if err := config.repo.Update(i); err != nil {
+			return err
+		}
+		return tick(ctx, i.ID, config, start, periodic, end, paused)
 	case StateNotStarted:
-		i.StartTime = time.Now()
+		if err := config.checkGap(i); err != nil {
+			return err
+		}
+		i.StartTime = config.clock().Now()
+		// the config may have changed since the interval was created
+		i.PlannedDuration = config.plannedDuration(i)
 		fallthrough
 	case StatePaused:
+		if err := config.validateTransition(from, StateRunning, i); err != nil {
+			return err
+		}
+		if from == StateNotStarted && config.CleanupNotStarted {
+			if err := config.cleanupNotStarted(i.ID); err != nil {
+				return err
+			}
+		}
 		i.State = StateRunning
+		config.unpause(&i)
+		config.lock(&i)
 		if err := config.repo.Update(i); err != nil{
 			return err
 		}
-		return tick(ctx, i.ID, config, start, periodic, end)
+		if from == StatePaused {
+			config.resumed(i)
+		} else {
+			config.emit(EventStarted, i)
+		}
+		return tick(ctx, i.ID, config, start, periodic, end, paused)
 	case StateCancelled, StateDone:
 		return fmt.Errorf("%w: Cannot start", ErrIntervalCompleted)
+	case StatePendingConfirm:
+		return fmt.Errorf("%w: confirm the completion first", ErrInvalidState)
 	default:
 		return fmt.Errorf("%w: %d", ErrInvalidState, i.State)
 	}
@@ -272,15 +1222,376 @@ func (i Interval) Pause(config *IntervalConfig) error  {
 	/**
 	* Pause() - method allows callers to pause a running interval.
 			it verifies whether the instance of interval is running and pauses it by setting
-			the state to StatePaused
+			the state to StatePaused, each pause counts as an interruption and its time
+			is added to PausedDuration when the interval resumes
 	* @config: instance of IntervalConfig
 	* Returns: error
 	*/
+	// the timer of a running interval saves it on every tick, hold it off and pause the
+	// latest version so neither save overwrites the other
+	i, release, err := config.hold(i)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	if !i.IsValidState() {
+		return fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+	}
 	if i.State != StateRunning {
 		return ErrIntervalNotRunning
 	}
 
+	if err := config.validateTransition(i.State, StatePaused, i); err != nil {
+		return err
+	}
+
 	i.State = StatePaused
+	i.Interruptions++
+	i.PausedAt = config.clock().Now()
+	if err := config.update(i); err != nil {
+		return err
+	}
+	config.emit(EventPaused, i)
+
+	return nil
+}
+
+func (i Interval) Complete(config *IntervalConfig) error {
+	/**
+	* Complete() - method allows callers to end a running or paused interval early as done,
+			e.g. a task finished before the timer, keeping the time elapsed as its actual
+			duration. Unlike a cancelled one it counts as completed.
+	* @config: instance of IntervalConfig
+	* Returns: error, ErrIntervalCompleted when the interval is already done or cancelled
+	*/
+	// the timer of a running interval saves the time elapsed on every tick, hold it off and
+	// complete the latest version
+	i, release, err := config.hold(i)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	switch i.State {
+	case StateRunning, StatePaused:
+	case StateDone, StateCancelled:
+		return fmt.Errorf("%w: Cannot complete", ErrIntervalCompleted)
+	case StateNotStarted, StatePendingConfirm:
+		return ErrIntervalNotRunning
+	default:
+		return fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+	}
+
+	if err := config.validateTransition(i.State, StateDone, i); err != nil {
+		return err
+	}
+
+	i.State = StateDone
+	if err := config.update(i); err != nil {
+		return err
+	}
+	release() // the plugins may change the interval again, let the timer stop first
+	config.emit(EventCompleted, i)
+	config.afterDone(i)
+
+	return nil
+}
+
+func (i Interval) Cancel(config *IntervalConfig) error {
+	/**
+	* Cancel() - method allows callers to cancel an interval without cancelling the context
+			passed to Start, a running interval stops ticking on its next tick
+	* @config: instance of IntervalConfig
+	* Returns: error, ErrIntervalCompleted when the interval is already done or cancelled
+	*/
+	i, release, err := config.hold(i)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	if !i.IsValidState() {
+		return fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+	}
+	if i.State == StateDone || i.State == StateCancelled {
+		return fmt.Errorf("%w: Cannot cancel", ErrIntervalCompleted)
+	}
+
+	if err := config.validateTransition(i.State, StateCancelled, i); err != nil {
+		return err
+	}
+
+	i.State = StateCancelled
+	if err := config.update(i); err != nil {
+		return err
+	}
+	config.emit(EventCancelled, i)
+
+	return nil
+}
+
+func (i Interval) PauseAt(ctx context.Context, config *IntervalConfig, at time.Time) error {
+	/**
+	* PauseAt - method waits until the scheduled time, as told by the clock of the config,
+	*			and then pauses the interval, e.g. when a meeting starts
+	* @ctx: instance of context.Context, cancelling it aborts the scheduled pause
+	* @config: instance of IntervalConfig
+	* @at: time to pause the interval at, a time in the past pauses it right away
+	* Return: error, ErrIntervalCompleted when the interval has already ended or ctx.Err()
+			  when the context is cancelled first
+	*/
+	if i.State == StateDone || i.State == StateCancelled {
+		return fmt.Errorf("%w: Cannot pause", ErrIntervalCompleted)
+	}
+
+	clock := config.clock()
+	select {
+	case <-clock.After(at.Sub(clock.Now())):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	// the interval kept running while waiting, pause its current version
+	i, err := config.repo.ByID(i.ID)
+	if err != nil {
+		return err
+	}
+	if i.State == StateDone || i.State == StateCancelled {
+		return fmt.Errorf("%w: Cannot pause", ErrIntervalCompleted)
+	}
+
+	return i.Pause(config)
+}
+
+func (i Interval) ConvertCategory(config *IntervalConfig, newCategory string) (Interval, error) {
+	/**
+	* ConvertCategory - method changes the category of a running or paused interval, e.g. a
+	*					break that turns into work, keeping the time already elapsed. The
+	*					planned duration becomes the one configured for the new category and
+	*					the interval is completed right away if it has already run that long.
+	* @config: instance of IntervalConfig
+	* @newCategory: one of CategoryPomodoro, CategoryShortBreak or CategoryLongBreak
+	* Return: the converted interval or error, ErrInvalidCategory for an unknown category
+	*/
+	if !validCategory(newCategory) {
+		return i, fmt.Errorf("%w: %q", ErrInvalidCategory, newCategory)
+	}
+
+	// the timer of a running interval saves the time elapsed on every tick, hold it off and
+	// convert the latest version so neither save overwrites the other
+	i, release, err := config.hold(i)
+	defer release()
+	if err != nil {
+		return i, err
+	}
+
+	switch i.State {
+	case StateRunning, StatePaused:
+	case StateCancelled, StateDone:
+		return i, fmt.Errorf("%w: Cannot convert", ErrIntervalCompleted)
+	case StateNotStarted, StatePendingConfirm:
+		return i, ErrIntervalNotRunning
+	default:
+		return i, fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+	}
+
+	i.Category = newCategory
+	i.PlannedDuration = config.plannedDuration(i)
+
+	if i.ActualDuration < i.PlannedDuration {
+		return i, config.update(i)
+	}
+
+	if err := config.validateTransition(i.State, StateDone, i); err != nil {
+		return i, err
+	}
+	i.State = StateDone
+	if err := config.update(i); err != nil {
+		return i, err
+	}
+	release() // the plugins may change the interval again, let the timer stop first
+	config.emit(EventCompleted, i)
+	config.afterDone(i)
+
+	return i, nil
+}
+
+func (i Interval) SetMeta(config *IntervalConfig, key, value string) error {
+	/**
+	* SetMeta - method attaches a key/value pair to the metadata of an interval and saves it,
+	*			replacing the previous value of the key
+	* @config: instance of IntervalConfig
+	* @key: the key
+	* @value: the value
+	* Returns: error
+	*/
+	// copy the map, it may be shared with other copies of the interval held by the caller
+	meta := make(map[string]string, len(i.Metadata)+1)
+	for k, v := range i.Metadata {
+		meta[k] = v
+	}
+	meta[key] = value
+	i.Metadata = meta
+
+	return config.update(i)
+}
+
+func (i Interval) SetTags(config *IntervalConfig, tags ...string) error {
+	/**
+	* SetTags - method labels an interval with what it's spent on and saves it, e.g. before
+	*			starting it, replacing the previous tags
+	* @config: instance of IntervalConfig
+	* @tags: the tags, none removes them
+	* Returns: error
+	*/
+	// copy the tags, the slice may be reused by the caller
+	i.Tags = nil
+	if len(tags) > 0 {
+		i.Tags = append([]string{}, tags...)
+	}
+
+	return config.update(i)
+}
+
+func (config *IntervalConfig) SoftDelete(id int64) error {
+	/**
+	* SoftDelete - method marks an interval as deleted without removing it from the repository,
+	*			   it is excluded from queries and stats until it is restored
+	* @id: ID of the interval to delete
+	* Returns: error
+	*/
+	i, err := config.repo.ByID(id)
+	if err != nil {
+		return err
+	}
+
+	i.Deleted = true
+
+	return config.update(i)
+}
+
+func (config *IntervalConfig) cleanupNotStarted(id int64) error {
+	/**
+	* cleanupNotStarted - method soft-deletes the intervals left in StateNotStarted, except
+	*					  the one being started, so they can still be restored
+	* @id: ID of the interval being started
+	* Returns: error
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range intervals {
+		if i.ID == id || i.State != StateNotStarted {
+			continue
+		}
+		i.Deleted = true
+		if err := config.repo.Update(i); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (config *IntervalConfig) Restore(id int64) error {
+	/**
+	* Restore - method reverts SoftDelete, making the interval visible to queries and stats again
+	* @id: ID of the interval to restore
+	* Returns: error
+	*/
+	i, err := config.repo.ByID(id)
+	if err != nil {
+		return err
+	}
+
+	i.Deleted = false
+
+	return config.update(i)
+}
+
+func (config *IntervalConfig) ClearHistory() error {
+	/**
+	* ClearHistory - method removes every interval from the repository, including soft-deleted
+	*				 ones, and resets the IDs so the next interval gets ID 1. This can't be undone.
+	* Returns: error
+	*/
+	return config.repo.DeleteAll()
+}
+
+func DiffInterval(a, b Interval) []string {
+	/**
+	* DiffInterval - function compares two versions of the same interval field by field,
+	*				 times are compared as instants and empty slices or maps are equal to nil ones
+	* @a, @b: the versions to compare
+	* Return: names of the fields that differ, in declaration order
+	*/
+	fields := []string{}
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+
+	for k := 0; k < va.NumField(); k++ {
+		fa, fb := va.Field(k).Interface(), vb.Field(k).Interface()
+
+		switch va.Field(k).Kind() {
+		case reflect.Slice, reflect.Map:
+			if va.Field(k).Len() == 0 && vb.Field(k).Len() == 0 {
+				continue
+			}
+		}
+
+		if ta, ok := fa.(time.Time); ok {
+			if ta.Equal(fb.(time.Time)) {
+				continue
+			}
+		} else if reflect.DeepEqual(fa, fb) {
+			continue
+		}
+
+		fields = append(fields, va.Type().Field(k).Name)
+	}
+
+	return fields
+}
+
+// ResumeInfo describes an interval interrupted before it ended, for UIs to offer resuming it
+type ResumeInfo struct {
+	Interval       Interval      // the interrupted interval
+	Remaining      time.Duration // time left to complete it
+	InterruptedAgo time.Duration // time since it last made progress
+}
+
+func (config *IntervalConfig) ResumePrompt(now time.Time) (*ResumeInfo, error) {
+	/**
+	* ResumePrompt - method checks whether the last interval was left running or paused and
+	*				 describes it, without any printing so UIs can format it themselves.
+	*				 A paused interval last made progress when it was paused, a running one
+	*				 at its start time plus its actual and paused durations.
+	* @now: the current time
+	* Return: instance of ResumeInfo, nil when there's nothing to resume
+	*/
+	i, err := config.repo.Last()
+	if err == ErrNoIntervals {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if i.State != StateRunning && i.State != StatePaused {
+		return nil, nil
+	}
+
+	info := &ResumeInfo{Interval: i}
+	info.Remaining = i.Remaining()
+	progress := i.StartTime.Add(i.ActualDuration + i.PausedDuration)
+	if i.State == StatePaused && !i.PausedAt.IsZero() {
+		progress = i.PausedAt
+	}
+	if info.InterruptedAgo = now.Sub(progress); info.InterruptedAgo < 0 {
+		info.InterruptedAgo = 0
+	}
 
-	return config.repo.Update(i)
+	return info, nil
 }
@@ -2,8 +2,11 @@ package pomodoro_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -111,7 +114,7 @@ func TestGetInterval(t *testing.T) {
 			noop := func(pomodoro.Interval) {}
 
 			if err := res.Start(context.Background(), config,
-				noop, noop, noop); err != nil {
+				noop, noop, noop, noop); err != nil {
 				t.Fatal(err)
 			}
 
@@ -154,7 +157,7 @@ func TestPause(t *testing.T) {
 	testCases := []struct {
 		name        string
 		start       bool
-		expState    int
+		expState    pomodoro.State
 		expDuration time.Duration
 	}{
 		{name: "NotStarted", start: false,
@@ -184,11 +187,25 @@ func TestPause(t *testing.T) {
 					t.Fatal(err)
 				}
 			}
+			pausedCalls := 0
+			paused := func(i pomodoro.Interval) {
+				pausedCalls++
+				if i.State != pomodoro.StatePaused {
+					t.Errorf("Expected state %d, got %d.\n",
+						pomodoro.StatePaused, i.State)
+				}
+				if i.Interruptions != 1 {
+					t.Errorf("Expected 1 interruption, got %d.\n", i.Interruptions)
+				}
+			}
 
 			if tc.start {
-				if err := i.Start(ctx, config, start, periodic, end); err != nil {
+				if err := i.Start(ctx, config, start, periodic, end, paused); err != nil {
 					t.Fatal(err)
 				}
+				if pausedCalls != 1 {
+					t.Errorf("Expected paused callback once, got %d.\n", pausedCalls)
+				}
 			}
 
 			i, err = pomodoro.GetInterVal(config)
@@ -237,7 +254,7 @@ func TestStart(t *testing.T) {
 	testCases := []struct {
 		name        string
 		cancel      bool
-		expState    int
+		expState    pomodoro.State
 		expDuration time.Duration
 	}{
 
@@ -288,7 +305,11 @@ func TestStart(t *testing.T) {
 				}
 			}
 
-			if err := i.Start(ctx, config, start, periodic, end); err != nil {
+			paused := func(pomodoro.Interval) {
+				t.Errorf("Paused callback should not be executed")
+			}
+
+			if err := i.Start(ctx, config, start, periodic, end, paused); err != nil {
 				t.Fatal(err)
 			}
 
@@ -309,3 +330,2294 @@ func TestStart(t *testing.T) {
 		})
 	}
 }
+
+func TestBreakJitter(t *testing.T) {
+	const jitter = 0.2
+
+	// breakDurations creates a few cycles of intervals and returns the planned
+	// duration of each break keyed by its ID
+	breakDurations := func(t *testing.T, jitter float64) map[int64]time.Duration {
+		t.Helper()
+
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		config := pomodoro.NewConfig(repo, 0, 0, 0)
+		config.BreakJitter = jitter
+
+		durations := map[int64]time.Duration{}
+		for k := 0; k < 8; k++ {
+			i, err := pomodoro.GetInterVal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if i.Category != pomodoro.CategoryPomodoro {
+				durations[i.ID] = i.PlannedDuration
+			}
+
+			i.State = pomodoro.StateDone
+			if err := repo.Update(i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return durations
+	}
+
+	t.Run("Bounds", func(t *testing.T) {
+		for id, d := range breakDurations(t, jitter) {
+			base := 5 * time.Minute
+			if id%8 == 0 {
+				base = 15 * time.Minute
+			}
+			min := time.Duration(float64(base) * (1 - jitter))
+			max := time.Duration(float64(base) * (1 + jitter))
+			if d < min || d > max {
+				t.Errorf("Expected break %d duration within [%q, %q], got %q.\n",
+					id, min, max, d)
+			}
+		}
+	})
+
+	t.Run("Reproducible", func(t *testing.T) {
+		first := breakDurations(t, jitter)
+		second := breakDurations(t, jitter)
+		for id, d := range first {
+			if second[id] != d {
+				t.Errorf("Expected break %d duration %q, got %q.\n", id, d, second[id])
+			}
+		}
+	})
+
+	t.Run("NoJitter", func(t *testing.T) {
+		for id, d := range breakDurations(t, 0) {
+			if d != 5*time.Minute && d != 15*time.Minute {
+				t.Errorf("Expected break %d to keep its configured duration, got %q.\n", id, d)
+			}
+		}
+	})
+}
+
+func TestSoftDelete(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := time.Date(2023, time.May, 10, 12, 0, 0, 0, time.Local)
+	for k, category := range []string{
+		pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro,
+	} {
+		if _, err := repo.Create(pomodoro.Interval{
+			StartTime:      day.Add(time.Duration(k) * time.Hour),
+			ActualDuration: 5 * time.Minute,
+			Category:       category,
+			State:          pomodoro.StateDone,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	// check verifies the ID returned by Last, the number of breaks and the
+	// number of completed pomodoros visible to queries
+	check := func(t *testing.T, expLast int64, expBreaks, expDone int) {
+		t.Helper()
+
+		last, err := repo.Last()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if last.ID != expLast {
+			t.Errorf("Expected last ID %d, got %d.\n", expLast, last.ID)
+		}
+
+		breaks, err := repo.Breaks(3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(breaks) != expBreaks {
+			t.Errorf("Expected %d breaks, got %d.\n", expBreaks, len(breaks))
+		}
+
+		done, _, err := config.GoalProgress(day)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if done != expDone {
+			t.Errorf("Expected %d completed pomodoros, got %d.\n", expDone, done)
+		}
+	}
+
+	check(t, 3, 1, 2)
+
+	for _, id := range []int64{2, 3} {
+		if err := config.SoftDelete(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(t, 1, 0, 1)
+
+	i, err := repo.ByID(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !i.Deleted {
+		t.Errorf("Expected interval 3 to be kept as deleted")
+	}
+
+	for _, id := range []int64{2, 3} {
+		if err := config.Restore(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check(t, 3, 1, 2)
+}
+
+func TestSettings(t *testing.T) {
+	var repo pomodoro.Repository
+	config := pomodoro.NewConfig(repo, 20*time.Minute, 0, 30*time.Minute)
+	config.BreakJitter = 0.1
+	config.MaxResumeGap = time.Hour
+
+	expected := pomodoro.Settings{
+		PomodoroDuration:   20 * time.Minute,
+		ShortBreakDuration: 5 * time.Minute,
+		LongBreakDuration:  30 * time.Minute,
+		LongBreakInterval:  4,
+		BreakJitter:        0.1,
+		DailyGoal:          8,
+		MaxResumeGap:       time.Hour,
+		TickInterval:       time.Second,
+	}
+
+	if s := config.Settings(); s != expected {
+		t.Errorf("Expected settings %+v, got %+v.\n", expected, s)
+	}
+}
+
+func TestMaxResumeGap(t *testing.T) {
+	testCases := []struct {
+		name     string
+		started  time.Duration
+		expState pomodoro.State
+		expError error
+	}{
+		{name: "BelowCap", started: 10 * time.Minute,
+			expState: pomodoro.StateDone, expError: nil},
+		{name: "AboveCap", started: 2 * time.Hour,
+			expState: pomodoro.StateCancelled, expError: pomodoro.ErrIntervalStale},
+	}
+
+	// Execute tests for MaxResumeGap
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			config.MaxResumeGap = time.Hour
+
+			id, err := repo.Create(pomodoro.Interval{
+				StartTime:       time.Now().Add(-tc.started),
+				PlannedDuration: 2 * time.Millisecond,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StatePaused,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			i, err := pomodoro.GetInterVal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.ID != id {
+				t.Fatalf("Expected paused interval %d, got %d.\n", id, i.ID)
+			}
+
+			noop := func(pomodoro.Interval) {}
+			err = i.Start(context.Background(), config, noop, noop, noop, noop)
+			if !errors.Is(err, tc.expError) {
+				t.Errorf("Expected error %v, got %v.\n", tc.expError, err)
+			}
+
+			i, err = repo.ByID(id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.State != tc.expState {
+				t.Errorf("Expected state %d, got %d.\n", tc.expState, i.State)
+			}
+		})
+	}
+}
+
+func TestStartRefreshesPlannedDuration(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, time.Minute, 0, 0)
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.PlannedDuration != time.Minute {
+		t.Fatalf("Expected PlannedDuration %q, got %q.\n", time.Minute, i.PlannedDuration)
+	}
+
+	// settings edited before the interval is started
+	config.PomodoroDuration = 2 * time.Millisecond
+
+	noop := func(pomodoro.Interval) {}
+	if err := i.Start(context.Background(), config, noop, noop, noop, noop); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.PlannedDuration != config.PomodoroDuration {
+		t.Errorf("Expected PlannedDuration %q, got %q.\n", config.PomodoroDuration, i.PlannedDuration)
+	}
+	if i.State != pomodoro.StateDone {
+		t.Errorf("Expected state %d, got %d.\n", pomodoro.StateDone, i.State)
+	}
+}
+
+func TestClearHistory(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	for k := 0; k < 3; k++ {
+		if _, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := config.SoftDelete(3); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.ClearHistory(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+	}
+
+	id, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Errorf("Expected ID 1 after clearing, got %d.\n", id)
+	}
+}
+
+func TestSkipLongBreaks(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	config.SkipLongBreaks = true
+
+	// next creates the next interval and marks it as done
+	next := func(t *testing.T) pomodoro.Interval {
+		t.Helper()
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i.State = pomodoro.StateDone
+		if err := repo.Update(i); err != nil {
+			t.Fatal(err)
+		}
+		return i
+	}
+
+	for k := 1; k <= 16; k++ {
+		i := next(t)
+		if i.Category == pomodoro.CategoryLongBreak {
+			t.Fatalf("Expected no long breaks, got one at interval %d.\n", i.ID)
+		}
+		if k%2 == 0 && i.Category != pomodoro.CategoryShortBreak {
+			t.Errorf("Expected short break at interval %d, got %q.\n", i.ID, i.Category)
+		}
+	}
+
+	config.SkipLongBreaks = false
+	next(t)
+	if i := next(t); i.Category != pomodoro.CategoryLongBreak {
+		t.Errorf("Expected long break once the option is disabled, got %q.\n", i.Category)
+	}
+}
+
+func TestTransitionValidator(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	errNoPausingBreaks := errors.New("breaks can't be paused")
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	config.TransitionValidator = func(from, to pomodoro.State, i pomodoro.Interval) error {
+		if to == pomodoro.StatePaused && i.Category != pomodoro.CategoryPomodoro {
+			return errNoPausingBreaks
+		}
+		return nil
+	}
+
+	testCases := []struct {
+		name     string
+		category string
+		expState pomodoro.State
+		expError error
+	}{
+		{name: "PomodoroPaused", category: pomodoro.CategoryPomodoro,
+			expState: pomodoro.StatePaused, expError: nil},
+		{name: "BreakNotPaused", category: pomodoro.CategoryShortBreak,
+			expState: pomodoro.StateRunning, expError: errNoPausingBreaks},
+	}
+
+	// Execute tests for TransitionValidator
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			i := pomodoro.Interval{
+				StartTime:       time.Now(),
+				PlannedDuration: time.Minute,
+				Category:        tc.category,
+				State:           pomodoro.StateRunning,
+			}
+
+			var err error
+			if i.ID, err = repo.Create(i); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := i.Pause(config); !errors.Is(err, tc.expError) {
+				t.Errorf("Expected error %v, got %v.\n", tc.expError, err)
+			}
+
+			if i, err = repo.ByID(i.ID); err != nil {
+				t.Fatal(err)
+			}
+			if i.State != tc.expState {
+				t.Errorf("Expected state %d, got %d.\n", tc.expState, i.State)
+			}
+		})
+	}
+}
+
+func TestDeepPomodoro(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	testCases := []struct {
+		deep        bool
+		expCategory string
+	}{
+		{expCategory: pomodoro.CategoryPomodoro},
+		{expCategory: pomodoro.CategoryShortBreak},
+		{expCategory: pomodoro.CategoryPomodoro, deep: true},
+		{expCategory: pomodoro.CategoryLongBreak},
+		{expCategory: pomodoro.CategoryPomodoro},
+		{expCategory: pomodoro.CategoryShortBreak},
+	}
+
+	for k, tc := range testCases {
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Category != tc.expCategory {
+			t.Errorf("Expected interval %d category %q, got %q.\n", k+1, tc.expCategory, i.Category)
+		}
+
+		i.Deep = tc.deep
+		i.State = pomodoro.StateDone
+		if err := repo.Update(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDiffInterval(t *testing.T) {
+	base := pomodoro.Interval{
+		ID:              1,
+		StartTime:       time.Date(2023, time.May, 10, 9, 0, 0, 0, time.UTC),
+		PlannedDuration: 25 * time.Minute,
+		ActualDuration:  10 * time.Minute,
+		Category:        pomodoro.CategoryPomodoro,
+		State:           pomodoro.StateRunning,
+		Tags:            []string{"writing"},
+	}
+
+	testCases := []struct {
+		name   string
+		change func(i pomodoro.Interval) pomodoro.Interval
+		exp    []string
+	}{
+		{name: "Identical",
+			change: func(i pomodoro.Interval) pomodoro.Interval { return i },
+			exp:    []string{}},
+		{name: "SameInstantOtherZone",
+			change: func(i pomodoro.Interval) pomodoro.Interval {
+				i.StartTime = i.StartTime.In(time.FixedZone("UTC+1", 3600))
+				return i
+			},
+			exp: []string{}},
+		{name: "State",
+			change: func(i pomodoro.Interval) pomodoro.Interval {
+				i.State = pomodoro.StatePaused
+				return i
+			},
+			exp: []string{"State"}},
+		{name: "DurationAndTags",
+			change: func(i pomodoro.Interval) pomodoro.Interval {
+				i.ActualDuration = 11 * time.Minute
+				i.Tags = []string{"writing", "review"}
+				return i
+			},
+			exp: []string{"ActualDuration", "Tags"}},
+		{name: "NoTags",
+			change: func(i pomodoro.Interval) pomodoro.Interval {
+				i.Tags = nil
+				return i
+			},
+			exp: []string{"Tags"}},
+	}
+
+	// Execute tests for DiffInterval
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res := pomodoro.DiffInterval(base, tc.change(base))
+
+			if len(res) != len(tc.exp) {
+				t.Fatalf("Expected fields %v, got %v.\n", tc.exp, res)
+			}
+			for k := range res {
+				if res[k] != tc.exp[k] {
+					t.Errorf("Expected fields %v, got %v.\n", tc.exp, res)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionTag(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	const duration = time.Millisecond
+	config := pomodoro.NewConfig(repo, 3*duration, duration, 2*duration)
+	noop := func(pomodoro.Interval) {}
+
+	// run runs n intervals back to back
+	run := func(t *testing.T, n int) {
+		t.Helper()
+
+		for k := 0; k < n; k++ {
+			i, err := pomodoro.GetInterVal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := i.Start(context.Background(), config, noop, noop, noop, noop); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	run(t, 2)
+	config.SessionTag = "deep-work"
+	run(t, 3)
+	config.SessionTag = ""
+	run(t, 1)
+
+	res, err := repo.BySessionTag("deep-work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expIDs := []int64{3, 4, 5}
+	if len(res) != len(expIDs) {
+		t.Fatalf("Expected %d intervals, got %d.\n", len(expIDs), len(res))
+	}
+
+	var focus time.Duration
+	for k, i := range res {
+		if i.ID != expIDs[k] {
+			t.Errorf("Expected interval ID %d, got %d.\n", expIDs[k], i.ID)
+		}
+		if i.Category == pomodoro.CategoryPomodoro && i.State == pomodoro.StateDone {
+			focus += i.PlannedDuration
+		}
+	}
+
+	// intervals 3 and 5 are pomodoros
+	if focus != 6*duration {
+		t.Errorf("Expected session focus %q, got %q.\n", 6*duration, focus)
+	}
+}
+
+func TestResumePrompt(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 12, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expInfo   *pomodoro.ResumeInfo
+	}{
+		{name: "Empty"},
+		{name: "Done",
+			intervals: []pomodoro.Interval{{
+				StartTime:       now.Add(-time.Hour),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  25 * time.Minute,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StateDone,
+			}}},
+		{name: "StaleRunning",
+			intervals: []pomodoro.Interval{{
+				StartTime:       now.Add(-time.Hour),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  10 * time.Minute,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StateRunning,
+			}},
+			expInfo: &pomodoro.ResumeInfo{
+				Remaining:      15 * time.Minute,
+				InterruptedAgo: 50 * time.Minute,
+			}},
+		// paused for 20 minutes, then resumed and left running
+		{name: "RunningAfterPause",
+			intervals: []pomodoro.Interval{{
+				StartTime:       now.Add(-time.Hour),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  10 * time.Minute,
+				PausedDuration:  20 * time.Minute,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StateRunning,
+			}},
+			expInfo: &pomodoro.ResumeInfo{
+				Remaining:      15 * time.Minute,
+				InterruptedAgo: 30 * time.Minute,
+			}},
+		// paused a second time since
+		{name: "PausedAgain",
+			intervals: []pomodoro.Interval{{
+				StartTime:       now.Add(-time.Hour),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  10 * time.Minute,
+				PausedDuration:  20 * time.Minute,
+				PausedAt:        now.Add(-30 * time.Minute),
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StatePaused,
+			}},
+			expInfo: &pomodoro.ResumeInfo{
+				Remaining:      15 * time.Minute,
+				InterruptedAgo: 30 * time.Minute,
+			}},
+	}
+
+	// Execute tests for ResumePrompt
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			for _, i := range tc.intervals {
+				if _, err := repo.Create(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			info, err := config.ResumePrompt(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tc.expInfo == nil {
+				if info != nil {
+					t.Errorf("Expected nothing to resume, got %+v.\n", info)
+				}
+				return
+			}
+
+			if info == nil {
+				t.Fatal("Expected an interval to resume, got nil")
+			}
+			if info.Interval.ID != 1 {
+				t.Errorf("Expected interval ID 1, got %d.\n", info.Interval.ID)
+			}
+			if info.Remaining != tc.expInfo.Remaining {
+				t.Errorf("Expected remaining %q, got %q.\n", tc.expInfo.Remaining, info.Remaining)
+			}
+			if info.InterruptedAgo != tc.expInfo.InterruptedAgo {
+				t.Errorf("Expected interrupted %q ago, got %q.\n",
+					tc.expInfo.InterruptedAgo, info.InterruptedAgo)
+			}
+		})
+	}
+}
+
+func TestPauseAt(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	t.Run("Scheduled", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(now)
+		config := pomodoro.NewConfig(repo, 25*time.Minute, 0, 0)
+		config.Clock = clock
+
+		i := pomodoro.Interval{StartTime: now, PlannedDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateRunning}
+		var err error
+		if i.ID, err = repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- i.PauseAt(context.Background(), config, now.Add(10*time.Minute))
+		}()
+
+		clock.waitTimer(t)
+		clock.Advance(9 * time.Minute)
+		if i, err = repo.ByID(i.ID); err != nil {
+			t.Fatal(err)
+		}
+		if i.State != pomodoro.StateRunning {
+			t.Fatalf("Expected state %d before the scheduled time, got %d.\n", pomodoro.StateRunning, i.State)
+		}
+
+		clock.Advance(time.Minute)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+		waitState(t, repo, i.ID, pomodoro.StatePaused)
+	})
+
+	t.Run("Cancelled", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		config := pomodoro.NewConfig(repo, 25*time.Minute, 0, 0)
+		config.Clock = newFakeClock(now)
+
+		i := pomodoro.Interval{StartTime: now, Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StateRunning}
+		var err error
+		if i.ID, err = repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := i.PauseAt(ctx, config, now.Add(time.Hour)); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected error %q, got %q.\n", context.Canceled, err)
+		}
+		waitState(t, repo, i.ID, pomodoro.StateRunning)
+	})
+
+	t.Run("Ended", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		config := pomodoro.NewConfig(repo, 25*time.Minute, 0, 0)
+		config.Clock = newFakeClock(now)
+
+		i := pomodoro.Interval{StartTime: now, Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StateDone}
+		err := i.PauseAt(context.Background(), config, now.Add(time.Hour))
+		if !errors.Is(err, pomodoro.ErrIntervalCompleted) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrIntervalCompleted, err)
+		}
+	})
+}
+
+func TestNewConfigValidated(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    [3]time.Duration
+		expErr   error
+		expPomo  time.Duration
+		expShort time.Duration
+		expLong  time.Duration
+	}{
+		{name: "Zero", expErr: nil,
+			expPomo: 25 * time.Minute, expShort: 5 * time.Minute, expLong: 15 * time.Minute},
+		{name: "Positive", expErr: nil,
+			input:   [3]time.Duration{20 * time.Minute, 10 * time.Minute, 12 * time.Minute},
+			expPomo: 20 * time.Minute, expShort: 10 * time.Minute, expLong: 12 * time.Minute},
+		{name: "NegativePomodoro", expErr: pomodoro.ErrInvalidDuration,
+			input: [3]time.Duration{-5 * time.Minute, 0, 0}},
+		{name: "NegativeShortBreak", expErr: pomodoro.ErrInvalidDuration,
+			input: [3]time.Duration{0, -time.Second, 0}},
+		{name: "NegativeLongBreak", expErr: pomodoro.ErrInvalidDuration,
+			input: [3]time.Duration{20 * time.Minute, 0, -time.Minute}},
+	}
+
+	// Execute tests for NewConfigValidated
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var repo pomodoro.Repository
+			config, err := pomodoro.NewConfigValidated(repo, tc.input[0], tc.input[1], tc.input[2])
+			if tc.expErr != nil {
+				if !errors.Is(err, tc.expErr) {
+					t.Errorf("Expected error %q, got %q.\n", tc.expErr, err)
+				}
+				if config != nil {
+					t.Errorf("Expected no config, got %+v.\n", config)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %q.\n", err)
+			}
+
+			if config.PomodoroDuration != tc.expPomo || config.ShortBreakDuration != tc.expShort ||
+				config.LongBreakDuration != tc.expLong {
+				t.Errorf("Expected durations %q, %q, %q, got %q, %q, %q.\n",
+					tc.expPomo, tc.expShort, tc.expLong, config.PomodoroDuration,
+					config.ShortBreakDuration, config.LongBreakDuration)
+			}
+		})
+	}
+}
+
+func TestNewConfigWithActive(t *testing.T) {
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expID     int64
+		expState  pomodoro.State
+	}{
+		{name: "Empty", expID: 1, expState: pomodoro.StateNotStarted},
+		{name: "Running",
+			intervals: []pomodoro.Interval{
+				{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateRunning},
+			},
+			expID: 1, expState: pomodoro.StateRunning},
+		{name: "Done",
+			intervals: []pomodoro.Interval{
+				{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+			},
+			expID: 2, expState: pomodoro.StateNotStarted},
+	}
+
+	// Execute tests for NewConfigWithActive
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			for _, i := range tc.intervals {
+				if _, err := repo.Create(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if _, err := pomodoro.NewConfigWithActive(repo, 0, 0, 0); err != nil {
+				t.Fatal(err)
+			}
+
+			last, err := repo.Last()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if last.ID != tc.expID {
+				t.Errorf("Expected last ID %d, got %d.\n", tc.expID, last.ID)
+			}
+			if last.State != tc.expState {
+				t.Errorf("Expected state %d, got %d.\n", tc.expState, last.State)
+			}
+		})
+	}
+}
+
+func TestConvertCategory(t *testing.T) {
+	testCases := []struct {
+		name      string
+		state     pomodoro.State
+		elapsed   time.Duration
+		category  string
+		expState  pomodoro.State
+		expActual time.Duration
+		expError  error
+	}{
+		{name: "BelowPlanned", state: pomodoro.StateRunning, elapsed: 3 * time.Minute,
+			category: pomodoro.CategoryPomodoro, expState: pomodoro.StateRunning,
+			expActual: 3 * time.Minute},
+		{name: "Paused", state: pomodoro.StatePaused, elapsed: 3 * time.Minute,
+			category: pomodoro.CategoryPomodoro, expState: pomodoro.StatePaused,
+			expActual: 3 * time.Minute},
+		{name: "AbovePlanned", state: pomodoro.StateRunning, elapsed: 30 * time.Minute,
+			category: pomodoro.CategoryPomodoro, expState: pomodoro.StateDone,
+			expActual: 30 * time.Minute},
+		{name: "Done", state: pomodoro.StateDone, elapsed: 5 * time.Minute,
+			category: pomodoro.CategoryPomodoro, expState: pomodoro.StateDone,
+			expActual: 5 * time.Minute, expError: pomodoro.ErrIntervalCompleted},
+		{name: "InvalidCategory", state: pomodoro.StateRunning, elapsed: 3 * time.Minute,
+			category: "Nap", expState: pomodoro.StateRunning,
+			expActual: 3 * time.Minute, expError: pomodoro.ErrInvalidCategory},
+	}
+
+	// Execute tests for ConvertCategory
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			config := pomodoro.NewConfig(repo, 25*time.Minute, 5*time.Minute, 15*time.Minute)
+			i := pomodoro.Interval{
+				StartTime:       time.Now(),
+				PlannedDuration: 5 * time.Minute,
+				ActualDuration:  tc.elapsed,
+				Category:        pomodoro.CategoryShortBreak,
+				State:           tc.state,
+			}
+			var err error
+			if i.ID, err = repo.Create(i); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = i.ConvertCategory(config, tc.category)
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Expected error %v, got %v.\n", tc.expError, err)
+			}
+
+			res, err := repo.ByID(i.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.State != tc.expState {
+				t.Errorf("Expected state %d, got %d.\n", tc.expState, res.State)
+			}
+			if res.ActualDuration != tc.expActual {
+				t.Errorf("Expected actual duration %q, got %q.\n", tc.expActual, res.ActualDuration)
+			}
+			if tc.expError == nil {
+				if res.Category != tc.category {
+					t.Errorf("Expected category %q, got %q.\n", tc.category, res.Category)
+				}
+				if res.PlannedDuration != config.PomodoroDuration {
+					t.Errorf("Expected planned duration %q, got %q.\n",
+						config.PomodoroDuration, res.PlannedDuration)
+				}
+			}
+		})
+	}
+}
+
+func TestConvertCategoryWhileTicking(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 3*time.Second, time.Hour, time.Hour)
+	config.Clock = clock
+	if _, err := repo.Create(pomodoro.Interval{StartTime: clock.Now().Add(-time.Hour),
+		Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone}); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errCh := make(chan error)
+	noop := func(pomodoro.Interval) {}
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+	}()
+	for k := 0; k < 3; k++ {
+		clock.Tick(t, time.Second)
+	}
+	waitActual(t, repo, i.ID, 3*time.Second)
+
+	// i is the copy from before Start, the break has run long enough for a pomodoro since
+	i.State = pomodoro.StateRunning
+	if _, err := i.ConvertCategory(config, pomodoro.CategoryPomodoro); err != nil {
+		t.Fatal(err)
+	}
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	res := waitState(t, repo, i.ID, pomodoro.StateDone)
+	if res.Category != pomodoro.CategoryPomodoro || res.ActualDuration != 3*time.Second {
+		t.Errorf("Expected a pomodoro done after 3s, got %q after %q.\n", res.Category, res.ActualDuration)
+	}
+}
+
+// countingRepo counts the updates made to the wrapped repository
+type countingRepo struct {
+	pomodoro.Repository
+	updates int64
+}
+
+func (r *countingRepo) Update(i pomodoro.Interval) error {
+	atomic.AddInt64(&r.updates, 1)
+	return r.Repository.Update(i)
+}
+
+func TestPersistEvery(t *testing.T) {
+	r, cleanup := getRepo(t)
+	defer cleanup()
+
+	repo := &countingRepo{Repository: r}
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 30*time.Second, 0, 0)
+	config.Clock = clock
+	config.PersistEvery = 10 * time.Second
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, pause, resume := pomodoro.NewPausableContext(context.Background())
+	errCh := make(chan error)
+	noop := func(pomodoro.Interval) {}
+	go func() {
+		errCh <- i.Start(ctx, config, noop, noop, noop, noop)
+	}()
+
+	for k := 0; k < 15; k++ {
+		clock.Tick(t, time.Second)
+	}
+	if res, err := repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	} else if res.ActualDuration != 10*time.Second {
+		t.Errorf("Expected persisted duration %q, got %q.\n", 10*time.Second, res.ActualDuration)
+	}
+
+	// pausing flushes the running value
+	pause()
+	if res := waitState(t, repo, i.ID, pomodoro.StatePaused); res.ActualDuration != 15*time.Second {
+		t.Errorf("Expected duration %q on pause, got %q.\n", 15*time.Second, res.ActualDuration)
+	}
+	resume()
+
+	for k := 0; k < 15; k++ {
+		clock.Tick(t, time.Second)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	res := waitState(t, repo, i.ID, pomodoro.StateDone)
+	if res.ActualDuration != 30*time.Second {
+		t.Errorf("Expected duration %q, got %q.\n", 30*time.Second, res.ActualDuration)
+	}
+	if n := atomic.LoadInt64(&repo.updates); n >= 30 {
+		t.Errorf("Expected fewer updates than ticks, got %d.\n", n)
+	}
+}
+
+func TestPersistEveryUnsaved(t *testing.T) {
+	// starts an interval with an hour between the saves and runs it for 7 ticks, none of
+	// them saved, returning the function stopping its timer
+	start := func(t *testing.T, config *pomodoro.IntervalConfig,
+		clock *fakeClock) (pomodoro.Interval, context.CancelFunc, chan error) {
+		t.Helper()
+
+		config.Clock = clock
+		config.PersistEvery = time.Hour
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		ticks := make(chan pomodoro.Interval)
+		noop := func(pomodoro.Interval) {}
+		periodic := func(i pomodoro.Interval) { ticks <- i }
+		ctx, cancel := context.WithCancel(context.Background())
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- i.Start(ctx, config, noop, periodic, noop, noop)
+		}()
+		for k := 0; k < 7; k++ {
+			clock.Tick(t, time.Second)
+			<-ticks
+		}
+
+		return i, cancel, errCh
+	}
+
+	t.Run("Complete", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		spy := &spyNotifier{}
+		config.Notifier = spy
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		i, cancel, errCh := start(t, config, clock)
+		defer cancel()
+
+		// the copy of the caller is the one returned before the first tick
+		if err := i.Complete(config); err != nil {
+			t.Fatal(err)
+		}
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		res := waitState(t, repo, i.ID, pomodoro.StateDone)
+		if res.ActualDuration != 7*time.Second {
+			t.Errorf("Expected saved duration %q, got %q.\n", 7*time.Second, res.ActualDuration)
+		}
+		if len(spy.calls) != 1 || spy.calls[0].ActualDuration != 7*time.Second {
+			t.Fatalf("Expected a notification of %q, got %v.\n", 7*time.Second, spy.calls)
+		}
+	})
+
+	t.Run("ConvertCategory", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		config := pomodoro.NewConfig(repo, time.Hour, 5*time.Second, 0)
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		i, cancel, errCh := start(t, config, clock)
+
+		// the break is shorter than the time already run
+		res, err := i.ConvertCategory(config, pomodoro.CategoryShortBreak)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.State != pomodoro.StateDone || res.ActualDuration != 7*time.Second {
+			t.Errorf("Expected state %d after %q, got %d after %q.\n", pomodoro.StateDone,
+				7*time.Second, res.State, res.ActualDuration)
+		}
+		cancel()
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func TestNewIntervalOfCategory(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	i, err := pomodoro.NewIntervalOfCategory(config, pomodoro.CategoryLongBreak, "POMO-7", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Category != pomodoro.CategoryLongBreak || res.TaskID != "POMO-7" {
+		t.Errorf("Expected long break of task %q, got %q of %q.\n", "POMO-7", res.Category, res.TaskID)
+	}
+	if !res.Billable {
+		t.Error("Expected billable interval")
+	}
+	if res.State != pomodoro.StateNotStarted || res.PlannedDuration != config.LongBreakDuration {
+		t.Errorf("Expected not started interval of %q, got state %d of %q.\n",
+			config.LongBreakDuration, res.State, res.PlannedDuration)
+	}
+
+	if _, err := pomodoro.NewIntervalOfCategory(config, "Nap", "", false); !errors.Is(err, pomodoro.ErrInvalidCategory) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidCategory, err)
+	}
+}
+
+func TestOnResume(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 5*time.Second, 0, 0)
+	config.Clock = clock
+
+	var starts, resumes int64
+	config.OnResume = func(pomodoro.Interval) { atomic.AddInt64(&resumes, 1) }
+	start := func(pomodoro.Interval) { atomic.AddInt64(&starts, 1) }
+	noop := func(pomodoro.Interval) {}
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(context.Background(), config, start, noop, noop, noop)
+	}()
+	clock.Tick(t, time.Second)
+
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Pause(config); err != nil {
+		t.Fatal(err)
+	}
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	if n := atomic.LoadInt64(&starts); n != 1 {
+		t.Errorf("Expected start to fire once on first start, got %d.\n", n)
+	}
+	if n := atomic.LoadInt64(&resumes); n != 0 {
+		t.Errorf("Expected OnResume not to fire on first start, got %d.\n", n)
+	}
+
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		errCh <- i.Start(context.Background(), config, start, noop, noop, noop)
+	}()
+	clock.Tick(t, time.Second)
+
+	if n := atomic.LoadInt64(&resumes); n != 1 {
+		t.Errorf("Expected OnResume to fire once on resume, got %d.\n", n)
+	}
+
+	for k := 0; k < 3; k++ {
+		clock.Tick(t, time.Second)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMinGapBetweenPomodoros(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 10, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name     string
+		ended    time.Duration
+		paused   time.Duration
+		expError error
+		expWait  string
+	}{
+		{name: "TooSoon", ended: 2 * time.Minute, expError: pomodoro.ErrTooSoon, expWait: "3m0s"},
+		{name: "AfterGap", ended: 5 * time.Minute},
+		// the pomodoro ended later than it would have without the pause
+		{name: "Paused", ended: time.Minute, paused: 14 * time.Minute, expError: pomodoro.ErrTooSoon,
+			expWait: "4m0s"},
+	}
+
+	// Execute tests for MinGapBetweenPomodoros
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			clock := newFakeClock(now)
+			config := pomodoro.NewConfig(repo, time.Second, 0, 0)
+			config.Clock = clock
+			config.MinGapBetweenPomodoros = 5 * time.Minute
+
+			if _, err := repo.Create(pomodoro.Interval{
+				StartTime:       now.Add(-tc.ended - tc.paused - 25*time.Minute),
+				PlannedDuration: 25 * time.Minute,
+				ActualDuration:  25 * time.Minute,
+				PausedDuration:  tc.paused,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           pomodoro.StateDone,
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			i, err := pomodoro.NewIntervalOfCategory(config, pomodoro.CategoryPomodoro, "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			noop := func(pomodoro.Interval) {}
+			if tc.expError != nil {
+				err := i.Start(context.Background(), config, noop, noop, noop, noop)
+				if !errors.Is(err, tc.expError) {
+					t.Fatalf("Expected error %q, got %q.\n", tc.expError, err)
+				}
+				if !strings.Contains(err.Error(), tc.expWait) {
+					t.Errorf("Expected remaining wait %s in %q.\n", tc.expWait, err)
+				}
+				waitState(t, repo, i.ID, pomodoro.StateNotStarted)
+				return
+			}
+
+			errCh := make(chan error)
+			go func() {
+				errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+			}()
+			clock.Tick(t, time.Second)
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+			waitState(t, repo, i.ID, pomodoro.StateDone)
+		})
+	}
+}
+
+func TestConfirmComplete(t *testing.T) {
+	testCases := []struct {
+		name    string
+		timeout time.Duration
+		confirm bool
+	}{
+		{name: "Confirm", confirm: true},
+		{name: "AutoConfirm", timeout: 10 * time.Second},
+	}
+
+	// Execute tests for ConfirmComplete
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+			config := pomodoro.NewConfig(repo, 2*time.Second, 0, 0)
+			config.Clock = clock
+			config.RequireCompletionConfirm = true
+			config.ConfirmTimeout = tc.timeout
+
+			i, err := pomodoro.GetInterVal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var ends int64
+			end := func(pomodoro.Interval) { atomic.AddInt64(&ends, 1) }
+			noop := func(pomodoro.Interval) {}
+			errCh := make(chan error)
+			go func() {
+				errCh <- i.Start(context.Background(), config, noop, noop, end, noop)
+			}()
+			clock.Tick(t, time.Second)
+			clock.Tick(t, time.Second)
+
+			res := waitState(t, repo, i.ID, pomodoro.StatePendingConfirm)
+			if n := atomic.LoadInt64(&ends); n != 0 {
+				t.Errorf("Expected end not to fire before confirmation, got %d.\n", n)
+			}
+
+			if tc.confirm {
+				if err := res.ConfirmComplete(config); err != nil {
+					t.Fatal(err)
+				}
+				clock.Tick(t, time.Second)
+			} else {
+				clock.waitTimer(t)
+				clock.Advance(tc.timeout)
+			}
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+
+			res = waitState(t, repo, i.ID, pomodoro.StateDone)
+			if n := atomic.LoadInt64(&ends); n != 1 {
+				t.Errorf("Expected end to fire once, got %d.\n", n)
+			}
+
+			if err := res.ConfirmComplete(config); !errors.Is(err, pomodoro.ErrInvalidState) {
+				t.Errorf("Expected error %q confirming again, got %q.\n", pomodoro.ErrInvalidState, err)
+			}
+		})
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	var repo pomodoro.Repository
+	base := pomodoro.NewConfig(repo, 20*time.Minute, 0, 0)
+	fp := base.Fingerprint()
+
+	same := pomodoro.NewConfig(repo, 20*time.Minute, 5*time.Minute, 15*time.Minute)
+	if same.Fingerprint() != fp {
+		t.Errorf("Expected identical configs to share fingerprint %s, got %s.\n", fp, same.Fingerprint())
+	}
+
+	testCases := []struct {
+		name   string
+		change func(c *pomodoro.IntervalConfig)
+	}{
+		{name: "Duration", change: func(c *pomodoro.IntervalConfig) { c.ShortBreakDuration = 10 * time.Minute }},
+		{name: "Flag", change: func(c *pomodoro.IntervalConfig) { c.SkipLongBreaks = true }},
+		{name: "Goal", change: func(c *pomodoro.IntervalConfig) { c.DailyGoal = 6 }},
+	}
+
+	// Execute tests for Fingerprint
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := pomodoro.NewConfig(repo, 20*time.Minute, 0, 0)
+			tc.change(c)
+			if c.Fingerprint() == fp {
+				t.Errorf("Expected a different fingerprint than %s.\n", fp)
+			}
+		})
+	}
+}
+
+func TestResumeAfterRestart(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	startTime := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	// relaunched an hour later
+	clock := newFakeClock(startTime.Add(time.Hour))
+	config := pomodoro.NewConfig(repo, 25*time.Minute, 0, 0)
+	config.Clock = clock
+
+	if _, err := repo.Create(pomodoro.Interval{
+		StartTime:       startTime,
+		PlannedDuration: 25 * time.Minute,
+		ActualDuration:  10 * time.Second,
+		Category:        pomodoro.CategoryPomodoro,
+		State:           pomodoro.StatePaused,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error)
+	noop := func(pomodoro.Interval) {}
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+	}()
+
+	res := waitState(t, repo, i.ID, pomodoro.StateRunning)
+	if !res.StartTime.Equal(startTime) {
+		t.Errorf("Expected StartTime %s to be preserved, got %s.\n", startTime, res.StartTime)
+	}
+
+	clock.waitTimer(t)
+	clock.Advance(24*time.Minute + 49*time.Second)
+	waitState(t, repo, i.ID, pomodoro.StateRunning)
+
+	clock.Advance(time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, repo, i.ID, pomodoro.StateDone)
+}
+
+func TestCancel(t *testing.T) {
+	testCases := []struct {
+		name     string
+		state    pomodoro.State
+		expState pomodoro.State
+		expError error
+	}{
+		{name: "Running", state: pomodoro.StateRunning, expState: pomodoro.StateCancelled},
+		{name: "Paused", state: pomodoro.StatePaused, expState: pomodoro.StateCancelled},
+		{name: "NotStarted", state: pomodoro.StateNotStarted, expState: pomodoro.StateCancelled},
+		{name: "Done", state: pomodoro.StateDone, expState: pomodoro.StateDone,
+			expError: pomodoro.ErrIntervalCompleted},
+		{name: "Cancelled", state: pomodoro.StateCancelled, expState: pomodoro.StateCancelled,
+			expError: pomodoro.ErrIntervalCompleted},
+	}
+
+	// Execute tests for Cancel
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			i := pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: tc.state}
+			var err error
+			if i.ID, err = repo.Create(i); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := i.Cancel(config); !errors.Is(err, tc.expError) {
+				t.Fatalf("Expected error %v, got %v.\n", tc.expError, err)
+			}
+			waitState(t, repo, i.ID, tc.expState)
+		})
+	}
+
+	t.Run("Ticking", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		config.Clock = clock
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errCh := make(chan error)
+		noop := func(pomodoro.Interval) {}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+
+		if i, err = repo.ByID(i.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := i.Cancel(config); err != nil {
+			t.Fatal(err)
+		}
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+		waitState(t, repo, i.ID, pomodoro.StateCancelled)
+	})
+
+	t.Run("ExpiringAfterCancel", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+		config.Clock = clock
+		spy := &spyNotifier{}
+		config.Notifier = spy
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errCh := make(chan error)
+		noop := func(pomodoro.Interval) {}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+		clock.Tick(t, time.Second)
+		running := waitActual(t, repo, i.ID, 2*time.Second)
+
+		// the timer expires before the next tick could notice the cancellation
+		if err := running.Cancel(config); err != nil {
+			t.Fatal(err)
+		}
+		clock.Advance(time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		waitState(t, repo, i.ID, pomodoro.StateCancelled)
+		if len(spy.calls) != 0 {
+			t.Errorf("Expected no notification for a cancelled interval, got %d.\n", len(spy.calls))
+		}
+	})
+
+	t.Run("StaleCopy", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		config.Clock = clock
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errCh := make(chan error)
+		noop := func(pomodoro.Interval) {}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		for k := 0; k < 3; k++ {
+			clock.Tick(t, time.Second)
+		}
+		waitActual(t, repo, i.ID, 3*time.Second)
+
+		// i is the copy from before Start, it hasn't run at all
+		if err := i.Cancel(config); err != nil {
+			t.Fatal(err)
+		}
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		res := waitState(t, repo, i.ID, pomodoro.StateCancelled)
+		if res.ActualDuration != 3*time.Second {
+			t.Errorf("Expected actual duration %q, got %q.\n", 3*time.Second, res.ActualDuration)
+		}
+	})
+}
+
+func TestSetTags(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 2*time.Second, 0, 0)
+	config.Clock = clock
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := []string{"writing", "review"}
+	if err := i.SetTags(config, tags...); err != nil {
+		t.Fatal(err)
+	}
+	tags[0] = "changed"
+
+	// the tags set before starting stay once the interval is done
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	noop := func(pomodoro.Interval) {}
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+	}()
+	clock.Tick(t, time.Second)
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.State != pomodoro.StateDone {
+		t.Errorf("Expected state %d, got %d.\n", pomodoro.StateDone, res.State)
+	}
+	if strings.Join(res.Tags, ",") != "writing,review" {
+		t.Errorf("Expected tags [writing review], got %v.\n", res.Tags)
+	}
+
+	if err := res.SetTags(config); err != nil {
+		t.Fatal(err)
+	}
+	if res, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Tags) != 0 {
+		t.Errorf("Expected tags to be removed, got %v.\n", res.Tags)
+	}
+}
+
+func TestSetMeta(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.SetMeta(config, "issue", "42"); err != nil {
+		t.Fatal(err)
+	}
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.SetMeta(config, "source", "jira"); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.SetMeta(config, "issue", "43"); err != nil {
+		t.Fatal(err)
+	}
+
+	if i.Metadata["source"] != "" {
+		t.Errorf("Expected the metadata of the caller's copy untouched, got %v.\n", i.Metadata)
+	}
+
+	res, err := repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]string{"issue": "43"}
+	if len(res.Metadata) != len(expected) || res.Metadata["issue"] != "43" {
+		t.Errorf("Expected metadata %v, got %v.\n", expected, res.Metadata)
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := pomodoro.Interval{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if diff := pomodoro.DiffInterval(res, decoded); len(diff) > 0 {
+		t.Errorf("Expected interval to survive JSON, differs in %v.\n", diff)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	expected := []string{
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak,
+		pomodoro.CategoryPomodoro, pomodoro.CategoryLongBreak,
+	}
+
+	for k, exp := range expected {
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Category != exp {
+			t.Fatalf("Expected interval %d to be %q, got %q.\n", k+1, exp, i.Category)
+		}
+
+		// every interval ends early, pomodoros after 10 minutes
+		i.State = pomodoro.StateRunning
+		i.ActualDuration = time.Minute
+		if i.Category == pomodoro.CategoryPomodoro {
+			i.ActualDuration = 10 * time.Minute
+		}
+		if err := repo.Update(i); err != nil {
+			t.Fatal(err)
+		}
+		if err := i.Complete(config); err != nil {
+			t.Fatal(err)
+		}
+
+		res := waitState(t, repo, i.ID, pomodoro.StateDone)
+		if res.ActualDuration != i.ActualDuration {
+			t.Errorf("Expected actual duration %q, got %q.\n", i.ActualDuration, res.ActualDuration)
+		}
+	}
+
+	i, err := repo.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Complete(config); !errors.Is(err, pomodoro.ErrIntervalCompleted) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrIntervalCompleted, err)
+	}
+}
+
+func TestCompleteWhileTicking(t *testing.T) {
+	t.Run("StaleCopy", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		config.Clock = clock
+		spy := &spyNotifier{}
+		config.Notifier = spy
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errCh := make(chan error)
+		noop := func(pomodoro.Interval) {}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		for k := 0; k < 3; k++ {
+			clock.Tick(t, time.Second)
+		}
+		waitActual(t, repo, i.ID, 3*time.Second)
+
+		// i is the copy from before Start, the time elapsed is the one ticked since
+		if err := i.Complete(config); err != nil {
+			t.Fatal(err)
+		}
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		res := waitState(t, repo, i.ID, pomodoro.StateDone)
+		if res.ActualDuration != 3*time.Second {
+			t.Errorf("Expected actual duration %q, got %q.\n", 3*time.Second, res.ActualDuration)
+		}
+		if len(spy.calls) != 1 || spy.calls[0].ActualDuration != 3*time.Second {
+			t.Errorf("Expected a single notification after 3s, got %v.\n", spy.calls)
+		}
+	})
+
+	t.Run("AfterExpiry", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+		config.Clock = clock
+		spy := &spyNotifier{}
+		config.Notifier = spy
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errCh := make(chan error)
+		noop := func(pomodoro.Interval) {}
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+		clock.Tick(t, time.Second)
+		running := waitActual(t, repo, i.ID, 2*time.Second)
+
+		clock.Advance(time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		// the copy still says running, the timer completed it already
+		if err := running.Complete(config); !errors.Is(err, pomodoro.ErrIntervalCompleted) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrIntervalCompleted, err)
+		}
+		if len(spy.calls) != 1 {
+			t.Errorf("Expected a single notification, got %d.\n", len(spy.calls))
+		}
+	})
+}
+
+func TestOnCycleComplete(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 2*time.Second, time.Second, 3*time.Second)
+	config.Clock = clock
+
+	type summary struct {
+		pomodoros int
+		focus     time.Duration
+	}
+	var summaries []summary
+	config.OnCycleComplete = func(pomodoros int, focus time.Duration) {
+		summaries = append(summaries, summary{pomodoros, focus})
+	}
+
+	// the previous cycle is not part of the summary
+	addIntervals(t, repo,
+		pomodoro.Interval{PlannedDuration: 2 * time.Second, ActualDuration: 2 * time.Second,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		pomodoro.Interval{PlannedDuration: 3 * time.Second, ActualDuration: 3 * time.Second,
+			Category: pomodoro.CategoryLongBreak, State: pomodoro.StateDone},
+	)
+
+	noop := func(pomodoro.Interval) {}
+	for n := 0; n < 8; n++ {
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		for d := time.Duration(0); d < i.PlannedDuration; d += time.Second {
+			clock.Tick(t, time.Second)
+		}
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		if n < 7 && len(summaries) != 0 {
+			t.Fatalf("Expected no summary before the long break, got %v after %s.\n",
+				summaries, i.Category)
+		}
+	}
+
+	last, err := repo.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.Category != pomodoro.CategoryLongBreak || last.State != pomodoro.StateDone {
+		t.Fatalf("Expected the cycle to end with a completed %s, got %s in state %d.\n",
+			pomodoro.CategoryLongBreak, last.Category, last.State)
+	}
+
+	exp := summary{pomodoros: 4, focus: 8 * time.Second}
+	if len(summaries) != 1 || summaries[0] != exp {
+		t.Errorf("Expected summary %v, got %v.\n", exp, summaries)
+	}
+}
+
+func TestCleanupNotStarted(t *testing.T) {
+	testCases := []struct {
+		name       string
+		cleanup    bool
+		expDeleted map[int64]bool
+	}{
+		{name: "Enabled", cleanup: true,
+			expDeleted: map[int64]bool{1: true, 2: true, 3: false, 4: false}},
+		{name: "Disabled", cleanup: false,
+			expDeleted: map[int64]bool{1: false, 2: false, 3: false, 4: false}},
+	}
+
+	// Execute tests for CleanupNotStarted
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			config := pomodoro.NewConfig(repo, time.Second, 0, 0)
+			config.Clock = newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+			config.CleanupNotStarted = tc.cleanup
+
+			// leftovers of earlier sessions, and a finished one to keep
+			addIntervals(t, repo,
+				pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateNotStarted},
+				pomodoro.Interval{Category: pomodoro.CategoryShortBreak, State: pomodoro.StateNotStarted},
+				pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+			)
+
+			i, err := pomodoro.NewIntervalOfCategory(config, pomodoro.CategoryPomodoro, "", false)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// the interval stops as soon as it starts
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			noop := func(pomodoro.Interval) {}
+			if err := i.Start(ctx, config, noop, noop, noop, noop); err != nil {
+				t.Fatal(err)
+			}
+
+			for id, expDeleted := range tc.expDeleted {
+				i, err := repo.ByID(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i.Deleted != expDeleted {
+					t.Errorf("Expected interval %d deleted %t, got %t.\n", id, expDeleted, i.Deleted)
+				}
+			}
+		})
+	}
+}
+
+func TestPomodorosBeforeLongBreak(t *testing.T) {
+	const (
+		p = pomodoro.CategoryPomodoro
+		s = pomodoro.CategoryShortBreak
+		l = pomodoro.CategoryLongBreak
+	)
+
+	testCases := []struct {
+		name   string
+		cycle  int
+		expSeq []string
+	}{
+		{name: "Default", cycle: 0,
+			expSeq: []string{p, s, p, s, p, s, p, l, p, s, p, s, p, s, p, l}},
+		{name: "Two", cycle: 2,
+			expSeq: []string{p, s, p, l, p, s, p, l}},
+		{name: "Four", cycle: 4,
+			expSeq: []string{p, s, p, s, p, s, p, l, p, s}},
+		{name: "One", cycle: 1,
+			expSeq: []string{p, l, p, l}},
+	}
+
+	// Execute tests for PomodorosBeforeLongBreak
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			if tc.cycle > 0 {
+				config.PomodorosBeforeLongBreak = tc.cycle
+			}
+			if exp := 4; tc.cycle == 0 && config.Settings().LongBreakInterval != exp {
+				t.Errorf("Expected default cycle of %d, got %d.\n", exp, config.Settings().LongBreakInterval)
+			}
+
+			for k, exp := range tc.expSeq {
+				i, err := pomodoro.GetInterVal(config)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if i.Category != exp {
+					t.Fatalf("Expected %s at %d, got %s.\n", exp, k, i.Category)
+				}
+
+				i.State = pomodoro.StateDone
+				if err := repo.Update(i); err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func TestCycleCadence(t *testing.T) {
+	interval := func(category string, state pomodoro.State) pomodoro.Interval {
+		return pomodoro.Interval{Category: category, State: state}
+	}
+	p := interval(pomodoro.CategoryPomodoro, pomodoro.StateDone)
+	c := interval(pomodoro.CategoryPomodoro, pomodoro.StateCancelled)
+	s := interval(pomodoro.CategoryShortBreak, pomodoro.StateDone)
+	l := interval(pomodoro.CategoryLongBreak, pomodoro.StateDone)
+
+	testCases := []struct {
+		name        string
+		intervals   []pomodoro.Interval
+		expCategory string
+	}{
+		{name: "Empty", expCategory: pomodoro.CategoryPomodoro},
+		{name: "FirstPomodoro", intervals: []pomodoro.Interval{p},
+			expCategory: pomodoro.CategoryShortBreak},
+		{name: "AfterBreak", intervals: []pomodoro.Interval{p, s},
+			expCategory: pomodoro.CategoryPomodoro},
+		{name: "FullCycle", intervals: []pomodoro.Interval{p, s, p, s, p, s, p},
+			expCategory: pomodoro.CategoryLongBreak},
+		{name: "AfterLongBreak", intervals: []pomodoro.Interval{p, s, p, s, p, s, p, l},
+			expCategory: pomodoro.CategoryPomodoro},
+		{name: "NewCycle", intervals: []pomodoro.Interval{p, s, p, s, p, s, p, l, p},
+			expCategory: pomodoro.CategoryShortBreak},
+		{name: "CancelledDontCount", intervals: []pomodoro.Interval{p, s, p, s, c, s, p},
+			expCategory: pomodoro.CategoryShortBreak},
+		{name: "SkippedBreaks", intervals: []pomodoro.Interval{p, p, p, p},
+			expCategory: pomodoro.CategoryLongBreak},
+		{name: "ExtraBreaks", intervals: []pomodoro.Interval{p, s, s, s, p},
+			expCategory: pomodoro.CategoryShortBreak},
+	}
+
+	// Execute tests for the cadence of the pomodoro cycle
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			i, err := pomodoro.GetInterVal(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.Category != tc.expCategory {
+				t.Errorf("Expected %s, got %s.\n", tc.expCategory, i.Category)
+			}
+		})
+	}
+
+	// walk a cycle from start to finish, a cancelled pomodoro makes it longer
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	walk := []pomodoro.Interval{p, s, c, s, p, s, p, s, p, l, p}
+	for k, exp := range walk {
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Category != exp.Category {
+			t.Fatalf("Expected %s at %d, got %s.\n", exp.Category, k, i.Category)
+		}
+
+		i.State = exp.State
+		if err := repo.Update(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUnknownState(t *testing.T) {
+	const unknown = 99
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	addIntervals(t, repo, pomodoro.Interval{StartTime: time.Now(), Category: pomodoro.CategoryPomodoro,
+		State: unknown})
+	i, err := repo.ByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	if i.IsValidState() {
+		t.Errorf("Expected state %d to be invalid", unknown)
+	}
+	if err := i.Validate(); !errors.Is(err, pomodoro.ErrInvalidState) {
+		t.Errorf("Validate: expected error %q, got %q.\n", pomodoro.ErrInvalidState, err)
+	}
+
+	noop := func(pomodoro.Interval) {}
+	actions := map[string]func() error{
+		"Start": func() error {
+			return i.Start(context.Background(), config, noop, noop, noop, noop)
+		},
+		"Pause":    func() error { return i.Pause(config) },
+		"Complete": func() error { return i.Complete(config) },
+		"Cancel":   func() error { return i.Cancel(config) },
+		"ConvertCategory": func() error {
+			_, err := i.ConvertCategory(config, pomodoro.CategoryShortBreak)
+			return err
+		},
+		"Status": func() error {
+			_, _, err := config.Status()
+			return err
+		},
+		"Summary": func() error {
+			_, err := config.Summary(time.Now())
+			return err
+		},
+		"DailyAggregates": func() error {
+			_, err := config.DailyAggregates(time.Now(), time.Now())
+			return err
+		},
+		"TotalFocusTime": func() error {
+			_, err := pomodoro.TotalFocusTime(repo)
+			return err
+		},
+	}
+	for name, action := range actions {
+		t.Run(name, func(t *testing.T) {
+			if err := action(); !errors.Is(err, pomodoro.ErrInvalidState) {
+				t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidState, err)
+			}
+		})
+	}
+
+	// nothing was changed
+	if i, err = repo.ByID(1); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != unknown {
+		t.Errorf("Expected state %d to be left alone, got %d.\n", unknown, i.State)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	testCases := []struct {
+		name     string
+		interval pomodoro.Interval
+		expErr   error
+	}{
+		{name: "Valid", expErr: nil,
+			interval: pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone}},
+		{name: "PendingConfirm", expErr: nil,
+			interval: pomodoro.Interval{Category: pomodoro.CategoryLongBreak,
+				State: pomodoro.StatePendingConfirm}},
+		{name: "NegativeState", expErr: pomodoro.ErrInvalidState,
+			interval: pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: -1}},
+		{name: "UnknownCategory", expErr: pomodoro.ErrInvalidCategory,
+			interval: pomodoro.Interval{Category: "Nap", State: pomodoro.StateDone}},
+	}
+
+	// Execute tests for Validate
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.interval.Validate()
+			if tc.expErr == nil && err != nil {
+				t.Errorf("Expected no error, got %q.\n", err)
+			}
+			if tc.expErr != nil && !errors.Is(err, tc.expErr) {
+				t.Errorf("Expected error %q, got %q.\n", tc.expErr, err)
+			}
+		})
+	}
+}
+
+func TestTickInterval(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	// runs on the system clock, a 1s tick would take real seconds
+	config := pomodoro.NewConfig(repo, 50*time.Millisecond, 0, 0)
+	config.TickInterval = 10 * time.Millisecond
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticks := 0
+	noop := func(pomodoro.Interval) {}
+	periodic := func(i pomodoro.Interval) {
+		ticks++
+		if exp := time.Duration(ticks) * config.TickInterval; i.ActualDuration != exp {
+			t.Errorf("Expected ActualDuration %q on tick %d, got %q.\n", exp, ticks, i.ActualDuration)
+		}
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, periodic, noop, noop)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected interval to complete promptly")
+	}
+
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StateDone {
+		t.Errorf("Expected state %d, got %d.\n", pomodoro.StateDone, i.State)
+	}
+	if ticks == 0 {
+		t.Error("Expected the interval to tick")
+	}
+}
+
+func TestNextIntervalWithNotice(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	var created []pomodoro.Interval
+	onNew := func(i pomodoro.Interval) { created = append(created, i) }
+
+	i, err := pomodoro.NextIntervalWithNotice(config, onNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 1 || created[0].ID != i.ID {
+		t.Fatalf("Expected callback with interval %d on creation, got %v.\n", i.ID, created)
+	}
+
+	// the interval isn't done yet, it is resumed
+	res, err := pomodoro.NextIntervalWithNotice(config, onNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != i.ID {
+		t.Errorf("Expected interval %d to be resumed, got %d.\n", i.ID, res.ID)
+	}
+	if len(created) != 1 {
+		t.Errorf("Expected no callback on resume, got %d calls.\n", len(created))
+	}
+
+	i.State = pomodoro.StateDone
+	if err := repo.Update(i); err != nil {
+		t.Fatal(err)
+	}
+	next, err := pomodoro.NextIntervalWithNotice(config, onNew)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(created) != 2 || created[1].Category != pomodoro.CategoryShortBreak {
+		t.Errorf("Expected callback with the %s following the pomodoro, got %v.\n",
+			pomodoro.CategoryShortBreak, created)
+	}
+	if next.ID == i.ID {
+		t.Errorf("Expected a new interval, got %d again.\n", next.ID)
+	}
+
+	// a nil callback is allowed
+	if _, err := pomodoro.NextIntervalWithNotice(config, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRemaining(t *testing.T) {
+	testCases := []struct {
+		name        string
+		planned     time.Duration
+		actual      time.Duration
+		expRemain   time.Duration
+		expProgress float64
+	}{
+		{name: "NotStarted", planned: 25 * time.Minute, actual: 0,
+			expRemain: 25 * time.Minute, expProgress: 0},
+		{name: "MidProgress", planned: 20 * time.Minute, actual: 5 * time.Minute,
+			expRemain: 15 * time.Minute, expProgress: 0.25},
+		{name: "Overrun", planned: 5 * time.Minute, actual: 7 * time.Minute,
+			expRemain: 0, expProgress: 1},
+		{name: "NoPlannedDuration", planned: 0, actual: 0,
+			expRemain: 0, expProgress: 1},
+	}
+
+	// Execute tests for Remaining
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			i := pomodoro.Interval{PlannedDuration: tc.planned, ActualDuration: tc.actual}
+
+			if r := i.Remaining(); r != tc.expRemain {
+				t.Errorf("Expected remaining %q, got %q.\n", tc.expRemain, r)
+			}
+			if p := i.Progress(); p != tc.expProgress {
+				t.Errorf("Expected progress %v, got %v.\n", tc.expProgress, p)
+			}
+		})
+	}
+}
+
+func TestStateString(t *testing.T) {
+	testCases := []struct {
+		state pomodoro.State
+		exp   string
+		value int
+	}{
+		{pomodoro.StateNotStarted, "NotStarted", 0},
+		{pomodoro.StateRunning, "Running", 1},
+		{pomodoro.StatePaused, "Paused", 2},
+		{pomodoro.StateDone, "Done", 3},
+		{pomodoro.StateCancelled, "Cancelled", 4},
+		{pomodoro.StatePendingConfirm, "PendingConfirm", 5},
+	}
+
+	// Execute tests for State.String
+	for _, tc := range testCases {
+		t.Run(tc.exp, func(t *testing.T) {
+			// the numeric values are stored by the repositories
+			if int(tc.state) != tc.value {
+				t.Errorf("Expected value %d, got %d.\n", tc.value, int(tc.state))
+			}
+			if s := tc.state.String(); s != tc.exp {
+				t.Errorf("Expected %q, got %q.\n", tc.exp, s)
+			}
+
+			for _, name := range []string{tc.exp, strings.ToLower(tc.exp), strings.ToUpper(tc.exp)} {
+				res, err := pomodoro.ParseState(name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if res != tc.state {
+					t.Errorf("Expected %q to parse as %s, got %s.\n", name, tc.state, res)
+				}
+			}
+		})
+	}
+
+	if s := pomodoro.State(9).String(); s != "State(9)" {
+		t.Errorf("Expected %q, got %q.\n", "State(9)", s)
+	}
+	if res, err := pomodoro.ParseState("pending_confirm"); err != nil || res != pomodoro.StatePendingConfirm {
+		t.Errorf("Expected %s, got %s (%v).\n", pomodoro.StatePendingConfirm, res, err)
+	}
+	for _, name := range []string{"", "Napping", "State(9)"} {
+		if _, err := pomodoro.ParseState(name); !errors.Is(err, pomodoro.ErrInvalidState) {
+			t.Errorf("Expected error %q for %q, got %q.\n", pomodoro.ErrInvalidState, name, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro/repository"
+)
+
+func TestJSONRepo(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pomo.json")
+
+		repo, err := repository.NewJSONRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+		intervals := []pomodoro.Interval{
+			{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+				Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Tags: []string{"a"}},
+			{StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute,
+				Category: pomodoro.CategoryShortBreak, State: pomodoro.StateRunning},
+		}
+		if err := pomodoro.SeedRepository(repo, intervals); err != nil {
+			t.Fatal(err)
+		}
+		last := intervals[1]
+		last.ID = 2
+		last.ActualDuration = 3 * time.Minute
+		if err := repo.Update(last); err != nil {
+			t.Fatal(err)
+		}
+
+		reloaded, err := repository.NewJSONRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := reloaded.Last()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := pomodoro.DiffInterval(last, res); len(diff) > 0 {
+			t.Errorf("Expected last interval to round-trip, differs in %v.\n", diff)
+		}
+		if first, err := reloaded.ByID(1); err != nil {
+			t.Fatal(err)
+		} else if first.Tags[0] != "a" {
+			t.Errorf("Expected tags %v, got %v.\n", intervals[0].Tags, first.Tags)
+		}
+
+		// new IDs follow the reloaded ones
+		if id, err := reloaded.Create(pomodoro.Interval{}); err != nil || id != 3 {
+			t.Errorf("Expected ID 3, got %d (%v).\n", id, err)
+		}
+	})
+
+	t.Run("Corrupted", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pomo.json")
+
+		repo, err := repository.NewJSONRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Create(pomodoro.Interval{ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Verify(); err != nil {
+			t.Fatalf("Expected the file to verify, got %q.\n", err)
+		}
+
+		// the file stays valid JSON, only the checksum tells
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		corrupted := strings.Replace(string(data), `"25m0s"`, `"52m0s"`, 1)
+		if corrupted == string(data) {
+			t.Fatalf("Expected the file to hold the actual duration, got %s.\n", data)
+		}
+		if err := os.WriteFile(path, []byte(corrupted), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := repo.Verify(); !errors.Is(err, pomodoro.ErrCorruptedData) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrCorruptedData, err)
+		}
+		if _, err := repository.NewJSONRepo(path); !errors.Is(err, pomodoro.ErrCorruptedData) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrCorruptedData, err)
+		}
+	})
+
+	t.Run("WithoutChecksum", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pomo.json")
+		data := `[{"ID": 1, "Category": "Pomodoro", "State": "Done"}]`
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		repo, err := repository.NewJSONRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i, err := repo.Last(); err != nil || i.ID != 1 {
+			t.Errorf("Expected interval 1, got %d (%v).\n", i.ID, err)
+		}
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		repo, err := repository.NewJSONRepo(filepath.Join(t.TempDir(), "pomo.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "pomo.json")
+		if err := os.WriteFile(path, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		repo, err := repository.NewJSONRepo(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+		}
+	})
+}
@@ -0,0 +1,73 @@
+package pomodoro
+
+/**
+* This module implements an advisory lock stored on the intervals, so processes sharing a
+* repository don't tick the same interval at once. The process ticking an interval refreshes
+* a heartbeat while it runs, a lock whose heartbeat is older than LockTimeout is stale.
+*/
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+func defaultLockOwner() string {
+	/**
+	* defaultLockOwner - function identifies the current process as host:pid
+	* Return: the identity
+	*/
+	host, err := os.Hostname()
+	if err != nil {
+		host = "localhost"
+	}
+
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+func (config *IntervalConfig) checkLock(i Interval) (bool, error) {
+	/**
+	* checkLock - method checks whether an interval is locked by another live owner
+	* @i: the interval to check
+	* Return: true if the interval holds a stale lock of another owner that can be taken
+	*		  over, ErrIntervalLockedElsewhere when the lock of another owner is live
+	*/
+	if config.LockTimeout <= 0 || i.LockedBy == "" || i.LockedBy == config.LockOwner {
+		return false, nil
+	}
+
+	if age := config.clock().Now().Sub(i.LockHeartbeat); age < config.LockTimeout {
+		return false, fmt.Errorf("%w: by %s, heartbeat %s ago", ErrIntervalLockedElsewhere,
+			i.LockedBy, age.Round(time.Second))
+	}
+
+	return true, nil
+}
+
+func (config *IntervalConfig) lock(i *Interval) {
+	/**
+	* lock - method takes the lock of an interval for the owner of the config, or refreshes
+	*		 its heartbeat, when locking is enabled. The interval still has to be saved.
+	* @i: the interval to lock
+	*/
+	if config.LockTimeout <= 0 {
+		return
+	}
+	i.LockedBy = config.LockOwner
+	i.LockHeartbeat = config.clock().Now()
+}
+
+func unlock(i *Interval) bool {
+	/**
+	* unlock - function releases the lock of an interval. The interval still has to be saved.
+	* @i: the interval to unlock
+	* Return: true if the interval was locked
+	*/
+	if i.LockedBy == "" {
+		return false
+	}
+	i.LockedBy = ""
+	i.LockHeartbeat = time.Time{}
+
+	return true
+}
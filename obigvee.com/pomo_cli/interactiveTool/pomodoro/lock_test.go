@@ -0,0 +1,93 @@
+package pomodoro_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestLock(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name      string
+		state     pomodoro.State
+		heartbeat time.Duration
+		expError  error
+	}{
+		{name: "LiveRunning", state: pomodoro.StateRunning, heartbeat: 5 * time.Second,
+			expError: pomodoro.ErrIntervalLockedElsewhere},
+		{name: "LivePaused", state: pomodoro.StatePaused, heartbeat: 5 * time.Second,
+			expError: pomodoro.ErrIntervalLockedElsewhere},
+		{name: "StaleRunning", state: pomodoro.StateRunning, heartbeat: time.Minute},
+		{name: "StalePaused", state: pomodoro.StatePaused, heartbeat: time.Minute},
+	}
+
+	// Execute tests for the locking of intervals
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			clock := newFakeClock(now)
+			config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+			config.Clock = clock
+			config.LockOwner = "this"
+			config.LockTimeout = 30 * time.Second
+
+			i := pomodoro.Interval{
+				StartTime:       now.Add(-time.Minute),
+				PlannedDuration: 3 * time.Second,
+				ActualDuration:  time.Second,
+				Category:        pomodoro.CategoryPomodoro,
+				State:           tc.state,
+				LockedBy:        "other",
+				LockHeartbeat:   now.Add(-tc.heartbeat),
+			}
+			var err error
+			if i.ID, err = repo.Create(i); err != nil {
+				t.Fatal(err)
+			}
+
+			noop := func(pomodoro.Interval) {}
+			if tc.expError != nil {
+				err := i.Start(context.Background(), config, noop, noop, noop, noop)
+				if !errors.Is(err, tc.expError) {
+					t.Fatalf("Expected error %q, got %q.\n", tc.expError, err)
+				}
+				if res, _ := repo.ByID(i.ID); res.State != tc.state || res.LockedBy != "other" {
+					t.Errorf("Expected interval locked by %q untouched, got state %d locked by %q.\n",
+						"other", res.State, res.LockedBy)
+				}
+				return
+			}
+
+			errCh := make(chan error)
+			go func() {
+				errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+			}()
+			clock.Tick(t, time.Second)
+
+			res, err := repo.ByID(i.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.LockedBy != "this" || res.LockHeartbeat.Before(now) {
+				t.Errorf("Expected lock taken over by %q since %s, got %q at %s.\n",
+					"this", now, res.LockedBy, res.LockHeartbeat)
+			}
+
+			clock.Tick(t, time.Second)
+			if err := <-errCh; err != nil {
+				t.Fatal(err)
+			}
+			res = waitState(t, repo, i.ID, pomodoro.StateDone)
+			if res.LockedBy != "" {
+				t.Errorf("Expected lock released when done, got %q.\n", res.LockedBy)
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package pomodoro
+
+/**
+* This module implements the notifications sent when an interval completes, e.g. a desktop
+* notification at the end of a pomodoro. The library doesn't depend on any notification
+* system, callers plug theirs in through the Notifier field of IntervalConfig.
+*/
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Notifier tells the user an interval is done
+type Notifier interface {
+	Notify(i Interval) error
+}
+
+// NopNotifier discards the notifications, it's the default of NewConfig
+type NopNotifier struct{}
+
+func (NopNotifier) Notify(Interval) error {
+	return nil
+}
+
+// StdoutNotifier prints a line for every completed interval
+type StdoutNotifier struct {
+	Out io.Writer // where the lines are printed, nil prints to the standard output
+}
+
+func (n StdoutNotifier) Notify(i Interval) error {
+	/**
+	* Notify - method prints the category and duration of the completed interval
+	* @i: the completed interval
+	* Return: error when the line can't be written
+	*/
+	out := n.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	_, err := fmt.Fprintf(out, "%s %d completed after %s\n", i.Category, i.ID, i.ActualDuration)
+	return err
+}
@@ -0,0 +1,128 @@
+package pomodoro_test
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+// spyNotifier records the intervals it's notified of
+type spyNotifier struct {
+	mu    sync.Mutex
+	calls []pomodoro.Interval
+}
+
+func (n *spyNotifier) Notify(i pomodoro.Interval) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.calls = append(n.calls, i)
+	return nil
+}
+
+func TestNotifier(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 2*time.Second, 2*time.Second, 0)
+	config.Clock = clock
+	spy := &spyNotifier{}
+	config.Notifier = spy
+
+	noop := func(pomodoro.Interval) {}
+	run := func(cancelAfter int) {
+		t.Helper()
+
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- i.Start(ctx, config, noop, noop, noop, noop)
+		}()
+		for k := 0; k < cancelAfter; k++ {
+			clock.Tick(t, time.Second)
+		}
+		if cancelAfter == 1 {
+			cancel()
+		}
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run(2) // the pomodoro completes
+	run(1) // the break is cancelled
+	run(1) // so is the next one
+
+	if len(spy.calls) != 1 {
+		t.Fatalf("Expected a single notification, got %d.\n", len(spy.calls))
+	}
+	if n := spy.calls[0]; n.ID != 1 || n.State != pomodoro.StateDone {
+		t.Errorf("Expected notification of completed interval 1, got %d in state %d.\n", n.ID, n.State)
+	}
+}
+
+func TestStdoutNotifier(t *testing.T) {
+	var out bytes.Buffer
+	n := pomodoro.StdoutNotifier{Out: &out}
+
+	err := n.Notify(pomodoro.Interval{ID: 3, Category: pomodoro.CategoryPomodoro,
+		ActualDuration: 25 * time.Minute, State: pomodoro.StateDone})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if exp := "Pomodoro 3 completed after 25m0s\n"; out.String() != exp {
+		t.Errorf("Expected %q, got %q.\n", exp, out.String())
+	}
+	if err := (pomodoro.NopNotifier{}).Notify(pomodoro.Interval{}); err != nil {
+		t.Errorf("Expected no error, got %q.\n", err)
+	}
+}
+
+func TestAlerter(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, time.Second, time.Second, 0)
+	config.Clock = clock
+
+	alerts := make(chan string) // unbuffered, a blocked alerter mustn't hold the timer
+	config.Alerter = func(category string) { alerts <- category }
+
+	noop := func(pomodoro.Interval) {}
+	for _, exp := range []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak} {
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errCh := make(chan error)
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case category := <-alerts:
+			if category != exp {
+				t.Errorf("Expected alert for %s, got %s.\n", exp, category)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected alert for %s", exp)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package pomodoro
+
+/**
+* This module implements a context that carries pause and resume requests to a running interval,
+* so callers can pause it without updating the repository themselves.
+*/
+
+import (
+	"context"
+	"sync"
+)
+
+type pauseKey struct{}
+
+// pauser holds the pause requests made through a pausable context
+type pauser struct {
+	mu     sync.Mutex
+	paused bool
+	notify chan struct{}
+	ack    chan struct{} // signalled once the timer handled a pause request
+}
+
+func newPauser() *pauser {
+	return &pauser{notify: make(chan struct{}, 1), ack: make(chan struct{}, 1)}
+}
+
+func NewPausableContext(parent context.Context) (context.Context, func(), func()) {
+	/**
+	* NewPausableContext - function returns a context to pass to Interval.Start along with
+	*					   functions to pause and resume the interval it runs. Pausing
+	*					   transitions the interval to StatePaused and resuming continues it
+	*					   from where it stopped, without returning from Start in between.
+	* @parent: the parent context, cancelling it cancels the interval as usual
+	* Return: the context, the pause function and the resume function
+	*/
+	p := newPauser()
+
+	return context.WithValue(parent, pauseKey{}, p), p.pause, p.resume
+}
+
+func pauserFrom(ctx context.Context) *pauser {
+	p, _ := ctx.Value(pauseKey{}).(*pauser)
+	return p
+}
+
+func (p *pauser) pause() {
+	p.set(true)
+}
+
+func (p *pauser) resume() {
+	p.set(false)
+}
+
+func (p *pauser) set(paused bool) {
+	p.mu.Lock()
+	p.paused = paused
+	p.mu.Unlock()
+
+	select {
+	case p.notify <- struct{}{}:
+	default: // a notification is already pending
+	}
+}
+
+func (p *pauser) isPaused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.paused
+}
+
+func (p *pauser) changed() <-chan struct{} {
+	/**
+	* changed - method returns the channel notified on every pause or resume request,
+	*			a nil pauser returns a nil channel that never delivers
+	*/
+	if p == nil {
+		return nil
+	}
+
+	return p.notify
+}
+
+func (p *pauser) acknowledge() {
+	select {
+	case p.ack <- struct{}{}:
+	default: // nobody is waiting for it
+	}
+}
+
+func (p *pauser) pauseAndWait(done <-chan struct{}) {
+	/**
+	* pauseAndWait - method requests a pause and waits until the timer handled it or stopped
+	* @done: closed when the timer stops
+	*/
+	select {
+	case <-p.ack: // drop an acknowledgement nobody waited for
+	default:
+	}
+	p.pause()
+
+	select {
+	case <-p.ack:
+	case <-done:
+	}
+}
@@ -0,0 +1,364 @@
+package pomodoro_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestPausableContext(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+	config.Clock = clock
+
+	ctx, pause, resume := pomodoro.NewPausableContext(context.Background())
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ticks := make(chan pomodoro.Interval, 10)
+	noop := func(pomodoro.Interval) {}
+	periodic := func(i pomodoro.Interval) { ticks <- i }
+	pauses := make(chan pomodoro.Interval, 1)
+	onPause := func(i pomodoro.Interval) { pauses <- i }
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(ctx, config, noop, periodic, noop, onPause)
+	}()
+
+	clock.Tick(t, time.Second)
+	<-ticks
+
+	pause()
+	paused := waitState(t, repo, i.ID, pomodoro.StatePaused)
+	select {
+	case p := <-pauses:
+		if p.State != pomodoro.StatePaused {
+			t.Errorf("Expected paused callback with state %d, got %d.\n",
+				pomodoro.StatePaused, p.State)
+		}
+	case <-time.After(time.Second):
+		t.Error("Paused callback was not executed")
+	}
+	if paused.ActualDuration != time.Second {
+		t.Errorf("Expected ActualDuration %q when paused, got %q.\n",
+			time.Second, paused.ActualDuration)
+	}
+
+	if paused.Interruptions != 1 {
+		t.Errorf("Expected 1 interruption, got %d.\n", paused.Interruptions)
+	}
+
+	// time spent paused doesn't count towards the interval
+	clock.Advance(10 * time.Second)
+
+	resume()
+	waitState(t, repo, i.ID, pomodoro.StateRunning)
+
+	clock.Tick(t, time.Second)
+	<-ticks
+	clock.Tick(t, time.Second)
+
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = repo.ByID(i.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StateDone {
+		t.Errorf("Expected state %d, got %d.\n", pomodoro.StateDone, i.State)
+	}
+	if i.ActualDuration != 3*time.Second {
+		t.Errorf("Expected ActualDuration %q, got %q.\n", 3*time.Second, i.ActualDuration)
+	}
+}
+
+func TestPausableContextCancel(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+	config.Clock = clock
+
+	parent, cancel := context.WithCancel(context.Background())
+	ctx, pause, _ := pomodoro.NewPausableContext(parent)
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(pomodoro.Interval) {}
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(ctx, config, noop, noop, noop, noop)
+	}()
+
+	clock.Tick(t, time.Second)
+	pause()
+	waitState(t, repo, i.ID, pomodoro.StatePaused)
+
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, repo, i.ID, pomodoro.StateCancelled)
+}
+
+func TestPausableContextStopped(t *testing.T) {
+	// starts an interval with a pausable context, returning the functions controlling it
+	// and the channel of the error of Start
+	start := func(t *testing.T, config *pomodoro.IntervalConfig) (pomodoro.Interval,
+		func(), context.CancelFunc, chan error) {
+		t.Helper()
+
+		parent, cancel := context.WithCancel(context.Background())
+		ctx, pause, _ := pomodoro.NewPausableContext(parent)
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		noop := func(pomodoro.Interval) {}
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- i.Start(ctx, config, noop, noop, noop, noop)
+		}()
+
+		return i, pause, cancel, errCh
+	}
+	wait := func(t *testing.T, errCh chan error) {
+		t.Helper()
+
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected the timer to stop")
+		}
+	}
+
+	t.Run("CancelledThenPaused", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		config.Clock = clock
+
+		i, pause, cancel, errCh := start(t, config)
+		defer cancel()
+		clock.Tick(t, time.Second)
+		running := waitActual(t, repo, i.ID, time.Second)
+
+		if err := running.Cancel(config); err != nil {
+			t.Fatal(err)
+		}
+		pause()
+		wait(t, errCh)
+
+		res := waitState(t, repo, i.ID, pomodoro.StateCancelled)
+		if res.Interruptions != 0 {
+			t.Errorf("Expected no interruption, got %d.\n", res.Interruptions)
+		}
+	})
+
+	t.Run("CompletedWhilePaused", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		config.Clock = clock
+		spy := &spyNotifier{}
+		config.Notifier = spy
+
+		i, pause, cancel, errCh := start(t, config)
+		defer cancel()
+		clock.Tick(t, time.Second)
+		pause()
+		paused := waitState(t, repo, i.ID, pomodoro.StatePaused)
+
+		if err := paused.Complete(config); err != nil {
+			t.Fatal(err)
+		}
+		// the timer notices on its next tick, cancelling afterwards changes nothing
+		clock.Tick(t, time.Second)
+		wait(t, errCh)
+		cancel()
+
+		res := waitState(t, repo, i.ID, pomodoro.StateDone)
+		if res.ActualDuration != time.Second {
+			t.Errorf("Expected actual duration %q, got %q.\n", time.Second, res.ActualDuration)
+		}
+		if len(spy.calls) != 1 {
+			t.Errorf("Expected a single notification, got %d.\n", len(spy.calls))
+		}
+	})
+
+	t.Run("ChangedWhilePaused", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+		config := pomodoro.NewConfig(repo, time.Hour, 0, 0)
+		config.Clock = clock
+
+		i, pause, cancel, errCh := start(t, config)
+		clock.Tick(t, time.Second)
+		pause()
+		paused := waitState(t, repo, i.ID, pomodoro.StatePaused)
+
+		if err := paused.SetTags(config, "writing"); err != nil {
+			t.Fatal(err)
+		}
+		cancel()
+		wait(t, errCh)
+
+		res := waitState(t, repo, i.ID, pomodoro.StateCancelled)
+		if len(res.Tags) != 1 || res.Tags[0] != "writing" {
+			t.Errorf("Expected the tags set while paused to be kept, got %v.\n", res.Tags)
+		}
+	})
+}
+
+func TestPausedDuration(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+	config.Clock = clock
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(pomodoro.Interval) {}
+	pause := func(i pomodoro.Interval) {
+		if err := i.Pause(config); err != nil {
+			t.Error(err)
+		}
+	}
+
+	// run one second, pause and stay paused for each of the gaps
+	var expPaused time.Duration
+	for _, gap := range []time.Duration{2 * time.Minute, 3 * time.Minute} {
+		errCh := make(chan error)
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, pause, noop, noop)
+		}()
+		clock.Tick(t, time.Second)
+		clock.Tick(t, 0) // the timer notices the pause
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+
+		if i, err = repo.ByID(i.ID); err != nil {
+			t.Fatal(err)
+		}
+		if i.PausedAt.IsZero() {
+			t.Fatal("Expected PausedAt to be set when paused")
+		}
+		clock.Advance(gap)
+		// the tick and the pause race with the clock, measure from the pause
+		expPaused += clock.Now().Sub(i.PausedAt)
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+	}()
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	if i, err = repo.ByID(i.ID); err != nil {
+		t.Fatal(err)
+	}
+	if i.State != pomodoro.StateDone {
+		t.Errorf("Expected state %d, got %d.\n", pomodoro.StateDone, i.State)
+	}
+	if i.ActualDuration != 3*time.Second {
+		t.Errorf("Expected ActualDuration %q, got %q.\n", 3*time.Second, i.ActualDuration)
+	}
+	if i.PausedDuration != expPaused || i.PausedDuration < 5*time.Minute {
+		t.Errorf("Expected PausedDuration %q, got %q.\n", expPaused, i.PausedDuration)
+	}
+	if !i.PausedAt.IsZero() {
+		t.Errorf("Expected PausedAt to be reset once resumed, got %s.\n", i.PausedAt)
+	}
+}
+
+// slowRepo widens the gap between reading an interval and saving it back
+type slowRepo struct {
+	pomodoro.Repository
+}
+
+func (r slowRepo) ByID(id int64) (pomodoro.Interval, error) {
+	i, err := r.Repository.ByID(id)
+	time.Sleep(time.Millisecond)
+	return i, err
+}
+
+func TestPauseWhileTicking(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	// the system clock with a fast tick, so pauses land while the timer saves its ticks
+	config := pomodoro.NewConfig(slowRepo{repo}, time.Second, 0, 0)
+	config.TickInterval = time.Millisecond
+
+	noop := func(pomodoro.Interval) {}
+	for n := 0; n < 20; n++ {
+		i, err := pomodoro.GetInterVal(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		errCh := make(chan error)
+		go func() {
+			errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+		}()
+		running := waitState(t, repo, i.ID, pomodoro.StateRunning)
+
+		time.Sleep(time.Duration(n%5) * time.Millisecond)
+		if err := running.Pause(config); err != nil {
+			t.Fatal(err)
+		}
+
+		select {
+		case err := <-errCh:
+			if err != nil {
+				t.Fatal(err)
+			}
+		case <-time.After(time.Second / 2):
+			t.Fatal("Expected the timer to stop once paused")
+		}
+
+		if i, err = repo.ByID(i.ID); err != nil {
+			t.Fatal(err)
+		}
+		if i.State != pomodoro.StatePaused {
+			t.Fatalf("Expected state %d after pause %d, got %d.\n", pomodoro.StatePaused, n, i.State)
+		}
+		if err := i.Cancel(config); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package pomodoro_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+// spyPlugin records the intervals it's called with and returns err
+type spyPlugin struct {
+	calls []pomodoro.Interval
+	err   error
+}
+
+func (p *spyPlugin) OnComplete(i pomodoro.Interval) error {
+	p.calls = append(p.calls, i)
+	return p.err
+}
+
+func TestPlugins(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	config := pomodoro.NewConfig(repo, time.Millisecond, 0, 0)
+	failing := &spyPlugin{err: errors.New("webhook unreachable")}
+	ok := &spyPlugin{}
+	config.RegisterPlugin(failing)
+	config.RegisterPlugin(ok)
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(pomodoro.Interval) {}
+	if err := i.Start(context.Background(), config, noop, noop, noop, noop); err != nil {
+		t.Fatalf("Expected plugin errors not to abort the timer, got %q.\n", err)
+	}
+
+	for _, p := range []*spyPlugin{failing, ok} {
+		if len(p.calls) != 1 {
+			t.Fatalf("Expected plugin to be called once, got %d calls.\n", len(p.calls))
+		}
+		if p.calls[0].ID != i.ID || p.calls[0].State != pomodoro.StateDone {
+			t.Errorf("Expected completed interval %d, got %d in state %d.\n",
+				i.ID, p.calls[0].ID, p.calls[0].State)
+		}
+	}
+
+	if !strings.Contains(logs.String(), "webhook unreachable") {
+		t.Errorf("Expected plugin error to be logged, got %q.\n", logs.String())
+	}
+}
+
+func TestPluginsNotCalledOnCancel(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, time.Minute, 0, 0)
+	p := &spyPlugin{}
+	config.RegisterPlugin(p)
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	start := func(pomodoro.Interval) { cancel() }
+	noop := func(pomodoro.Interval) {}
+	if err := i.Start(ctx, config, start, noop, noop, noop); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(p.calls) != 0 {
+		t.Errorf("Expected no plugin calls, got %d.\n", len(p.calls))
+	}
+}
@@ -0,0 +1,145 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro/repository"
+)
+
+// POMO_TEST_POSTGRES_DSN points at a disposable database, its intervals table is emptied
+const postgresDSNEnv = "POMO_TEST_POSTGRES_DSN"
+
+func getPostgresRepo(t *testing.T) interface {
+	pomodoro.Repository
+	Close() error
+} {
+	t.Helper()
+
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s not set", postgresDSNEnv)
+	}
+
+	repo, err := repository.NewPostgresRepo(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	return repo
+}
+
+func TestPostgresRepo(t *testing.T) {
+	repo := getPostgresRepo(t)
+	defer repo.Close()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.UTC)
+	intervals := []pomodoro.Interval{
+		{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone, Tags: []string{"a"}},
+		{StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute,
+			Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+		{StartTime: start.Add(30 * time.Minute), PlannedDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		{StartTime: start.Add(55 * time.Minute), PlannedDuration: 15 * time.Minute,
+			Category: pomodoro.CategoryLongBreak, State: pomodoro.StateRunning},
+	}
+	if err := pomodoro.SeedRepository(repo, intervals); err != nil {
+		t.Fatal(err)
+	}
+
+	last := intervals[3]
+	last.ID = 4
+	last.ActualDuration = 3 * time.Minute
+	if err := repo.Update(last); err != nil {
+		t.Fatal(err)
+	}
+	res, err := repo.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ID != last.ID || res.ActualDuration != last.ActualDuration || !res.StartTime.Equal(last.StartTime) {
+		t.Errorf("Expected last interval %+v, got %+v.\n", last, res)
+	}
+	if first, err := repo.ByID(1); err != nil {
+		t.Fatal(err)
+	} else if len(first.Tags) != 1 || first.Tags[0] != "a" {
+		t.Errorf("Expected tags %v, got %v.\n", intervals[0].Tags, first.Tags)
+	}
+
+	breaks, err := repo.Breaks(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(breaks) != 1 || breaks[0].ID != 4 {
+		t.Errorf("Expected the long break only, got %v.\n", breaks)
+	}
+	if breaks, err = repo.Breaks(0); err != nil {
+		t.Fatal(err)
+	} else if len(breaks) != 2 || breaks[1].ID != 2 {
+		t.Errorf("Expected both breaks most recent first, got %v.\n", breaks)
+	}
+
+	if between, err := repo.IntervalsBetween(start.Add(time.Minute), start.Add(50*time.Minute)); err != nil {
+		t.Fatal(err)
+	} else if len(between) != 2 || between[0].ID != 2 || between[1].ID != 3 {
+		t.Errorf("Expected intervals 2 and 3, got %v.\n", between)
+	}
+
+	if _, err := repo.ByID(99); !errors.Is(err, pomodoro.ErrInvalidID) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidID, err)
+	}
+	if err := repo.Update(pomodoro.Interval{ID: 99}); !errors.Is(err, pomodoro.ErrInvalidID) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidID, err)
+	}
+
+	if err := repo.DeleteAll(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+	}
+}
+
+func TestPostgresRepoConcurrent(t *testing.T) {
+	repo := getPostgresRepo(t)
+	defer repo.Close()
+
+	// two CLI instances sharing the database
+	other, err := repository.NewPostgresRepo(os.Getenv(postgresDSNEnv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	const perInstance = 20
+	var wg sync.WaitGroup
+	for _, r := range []pomodoro.Repository{repo, other} {
+		wg.Add(1)
+		go func(r pomodoro.Repository) {
+			defer wg.Done()
+			for n := 0; n < perInstance; n++ {
+				if _, err := r.Create(pomodoro.Interval{StartTime: time.Now(),
+					Category: pomodoro.CategoryPomodoro}); err != nil {
+					t.Error(err)
+				}
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	all, err := repo.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 2*perInstance {
+		t.Errorf("Expected %d intervals, got %d.\n", 2*perInstance, len(all))
+	}
+}
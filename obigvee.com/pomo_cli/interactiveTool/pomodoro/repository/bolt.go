@@ -0,0 +1,283 @@
+package repository
+
+/**
+* This module implements the Repository interface with a bbolt file, an embedded key/value
+* store without the cgo dependency of SQLite. Each interval is stored as JSON in a bucket,
+* keyed by its ID encoded in big-endian so the keys sort in creation order.
+*/
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+var intervalBucket = []byte("interval")
+
+type boltRepo struct {
+	db *bolt.DB // transactions of bbolt serialize the changes
+}
+
+func NewBoltRepo(path string) (*boltRepo, error) {
+	/**
+	* NewBoltRepo - function opens the bbolt file, creating it and the interval bucket on
+	*				first run
+	* @path: path of the database file
+	* Return: instance of boltRepo or error when the database can't be opened, e.g. when
+	*		  another process holds it
+	*/
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(intervalBucket)
+		return err
+	}); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltRepo{db: db}, nil
+}
+
+func (r *boltRepo) Close() error {
+	/**
+	* Close - method closes the database, releasing the file for other processes
+	*/
+	return r.db.Close()
+}
+
+func itob(id int64) []byte {
+	/**
+	* itob - function encodes an ID as a key, big-endian so keys sort by ID
+	* @id: the ID
+	* Return: the key
+	*/
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(id))
+
+	return key
+}
+
+func (r *boltRepo) scan(reverse bool, n int, match func(pomodoro.Interval) bool) ([]pomodoro.Interval, error) {
+	/**
+	* scan - method retrieves the intervals that are not soft-deleted and match a condition
+	* @reverse: walk the keys from the most recent interval
+	* @n: maximum number of intervals, zero or less retrieves all
+	* @match: the condition
+	* Return: intervals in key order, empty if there's none
+	*/
+	data := []pomodoro.Interval{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(intervalBucket).Cursor()
+		first, next := c.First, c.Next
+		if reverse {
+			first, next = c.Last, c.Prev
+		}
+
+		for k, v := first(); k != nil; k, v = next() {
+			i := pomodoro.Interval{}
+			if err := json.Unmarshal(v, &i); err != nil {
+				return err
+			}
+			if i.Deleted || !match(i) {
+				continue
+			}
+			data = append(data, i)
+			if len(data) == n {
+				return nil
+			}
+		}
+
+		return nil
+	})
+
+	return data, err
+}
+
+// Implementation of all the methods of the Repository interface using boltRepo type
+
+func (r *boltRepo) Create(i pomodoro.Interval) (int64, error) {
+	/**
+	* Create - method saves a new interval with the next ID of the bucket
+	* @i: the interval, its ID is ignored
+	* Return: ID of the saved entry
+	*/
+	err := r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(intervalBucket)
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+		i.ID = int64(seq)
+
+		data, err := json.Marshal(i)
+		if err != nil {
+			return err
+		}
+
+		return b.Put(itob(i.ID), data)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return i.ID, nil
+}
+
+func (r *boltRepo) Update(i pomodoro.Interval) error {
+	/**
+	* Update - method updates the values of an existing entry
+	* @i: the interval to save
+	* Return: error, pomodoro.ErrInvalidID when there's no interval with the ID
+	*/
+	data, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(intervalBucket)
+		if b.Get(itob(i.ID)) == nil {
+			return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+		}
+
+		return b.Put(itob(i.ID), data)
+	})
+}
+
+func (r *boltRepo) ByID(id int64) (pomodoro.Interval, error) {
+	/**
+	* ByID - method retrieve and return an item by its ID
+	* @id: id of data to retrieve
+	* Return: data by parsed id or pomodoro.ErrInvalidID if there's no such interval
+	*/
+	i := pomodoro.Interval{}
+	err := r.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(intervalBucket).Get(itob(id))
+		if v == nil {
+			return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+		}
+
+		return json.Unmarshal(v, &i)
+	})
+
+	return i, err
+}
+
+func (r *boltRepo) Last() (pomodoro.Interval, error) {
+	/**
+	* Last - method retrieves the most recently created interval that is not soft-deleted
+	* Return: last interval or pomodoro.ErrNoIntervals if the database is empty
+	*/
+	data, err := r.scan(true, 1, func(pomodoro.Interval) bool { return true })
+	if err != nil {
+		return pomodoro.Interval{}, err
+	}
+	if len(data) == 0 {
+		return pomodoro.Interval{}, pomodoro.ErrNoIntervals
+	}
+
+	return data[0], nil
+}
+
+func (r *boltRepo) Breaks(n int) ([]pomodoro.Interval, error) {
+	/**
+	* Breaks - method retrieves a given number n of the intervals of category break,
+	*		   most recent first
+	* @n: the value of the number to retrieve of category break, zero or less retrieves all
+	* Return: intervals or error if no data
+	*/
+	return r.scan(true, n, func(i pomodoro.Interval) bool {
+		return i.Category != pomodoro.CategoryPomodoro
+	})
+}
+
+func (r *boltRepo) ByProject(name string) ([]pomodoro.Interval, error) {
+	/**
+	* ByProject - method retrieves the intervals of a project in creation order
+	* @name: the name of the project
+	* Return: intervals of the project, empty if there's none
+	*/
+	return r.scan(false, 0, func(i pomodoro.Interval) bool { return i.Project == name })
+}
+
+func (r *boltRepo) DeleteAll() error {
+	/**
+	* DeleteAll - method removes every interval, IDs start from 1 again afterwards
+	*/
+	return r.db.Update(func(tx *bolt.Tx) error {
+		// a new bucket starts its sequence from zero
+		if err := tx.DeleteBucket(intervalBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucket(intervalBucket)
+		return err
+	})
+}
+
+func (r *boltRepo) BySessionTag(tag string) ([]pomodoro.Interval, error) {
+	/**
+	* BySessionTag - method retrieves the intervals of a tagged session in creation order
+	* @tag: the tag of the session
+	* Return: intervals of the session, empty if there's none
+	*/
+	return r.scan(false, 0, func(i pomodoro.Interval) bool { return i.SessionTag == tag })
+}
+
+func (r *boltRepo) ByDurationRange(min, max time.Duration) ([]pomodoro.Interval, error) {
+	/**
+	* ByDurationRange - method retrieves the intervals whose ActualDuration is within [min, max]
+	* @min: the shortest duration to retrieve
+	* @max: the longest duration to retrieve
+	* Return: intervals in creation order, empty if there's none
+	*/
+	return r.scan(false, 0, func(i pomodoro.Interval) bool {
+		return i.ActualDuration >= min && i.ActualDuration <= max
+	})
+}
+
+func (r *boltRepo) ByTaskID(id string) ([]pomodoro.Interval, error) {
+	/**
+	* ByTaskID - method retrieves the intervals logged against an external task in creation order
+	* @id: the ID of the task
+	* Return: intervals of the task, empty if there's none
+	*/
+	return r.scan(false, 0, func(i pomodoro.Interval) bool { return i.TaskID == id })
+}
+
+func (r *boltRepo) All() ([]pomodoro.Interval, error) {
+	/**
+	* All - method retrieves every interval that is not soft-deleted in creation order
+	* Return: intervals, empty if there's none
+	*/
+	return r.scan(false, 0, func(pomodoro.Interval) bool { return true })
+}
+
+func (r *boltRepo) IntervalsBetween(start, end time.Time) ([]pomodoro.Interval, error) {
+	/**
+	* IntervalsBetween - method retrieves the intervals started within [start, end) in
+	*					 chronological order
+	* @start: the earliest start time, inclusive
+	* @end: the latest start time, exclusive
+	* Return: intervals, empty if there's none
+	*/
+	data, err := r.scan(false, 0, func(i pomodoro.Interval) bool {
+		return !i.StartTime.Before(start) && i.StartTime.Before(end)
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the keys are in creation order, intervals can be created out of order
+	sort.SliceStable(data, func(a, b int) bool { return data[a].StartTime.Before(data[b].StartTime) })
+
+	return data, nil
+}
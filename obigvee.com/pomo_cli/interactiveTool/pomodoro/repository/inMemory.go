@@ -9,7 +9,9 @@ package repository
 
 import (
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
 	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
 )
@@ -17,6 +19,7 @@ import (
 type inMemoryRepo struct {
 	sync.RWMutex // mutexes prevents concurrent access to data
 	intervals [] pomodoro.Interval
+	breaks []int // positions of the breaks in intervals, in ascending order
 }
 
 func NewInMemoryRepo() *inMemoryRepo {
@@ -29,6 +32,27 @@ func NewInMemoryRepo() *inMemoryRepo {
 	}
 }
 
+func clone(i pomodoro.Interval) pomodoro.Interval {
+	/**
+	* clone - function copies an interval along with its tags and metadata, so the intervals
+	*		  stored can't be changed through the ones saved or retrieved by callers
+	* @i: the interval
+	* Return: the copy
+	*/
+	if i.Tags != nil {
+		i.Tags = append([]string{}, i.Tags...)
+	}
+	if i.Metadata != nil {
+		meta := make(map[string]string, len(i.Metadata))
+		for k, v := range i.Metadata {
+			meta[k] = v
+		}
+		i.Metadata = meta
+	}
+
+	return i
+}
+
 // Implementation of all the methods of the Repository interface using inMemoryRepo type
 
 func (r *inMemoryRepo) Create (i pomodoro.Interval) (int64, error){
@@ -43,7 +67,10 @@ func (r *inMemoryRepo) Create (i pomodoro.Interval) (int64, error){
 
 	i.ID = int64(len(r.intervals)) + 1
 
-	r.intervals = append(r.intervals, i)
+	r.intervals = append(r.intervals, clone(i))
+	if i.Category != pomodoro.CategoryPomodoro {
+		r.breaks = append(r.breaks, len(r.intervals)-1)
+	}
 
 	return i.ID, nil
 }
@@ -55,14 +82,51 @@ func (r *inMemoryRepo)  Update(i pomodoro.Interval) error {
 	
 	r.Lock() // prevents concurrent access to the data store while making changes to it.
 	defer r.Unlock()
-	if i.ID == 0 {
+	if i.ID <= 0 || i.ID > int64(len(r.intervals)) {
 		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
 	}
 	
-	r.intervals[i.ID-1] = i
+	r.reindex(int(i.ID-1), i.Category)
+	r.intervals[i.ID-1] = clone(i)
 	return nil
 }
 
+func (r *inMemoryRepo) rebuildIndex() {
+	/**
+	* rebuildIndex - method recreates the index of breaks from scratch, e.g. after loading
+	*				 the intervals, the caller must hold the lock
+	*/
+	r.breaks = nil
+	for k, i := range r.intervals {
+		if i.Category != pomodoro.CategoryPomodoro {
+			r.breaks = append(r.breaks, k)
+		}
+	}
+}
+
+func (r *inMemoryRepo) reindex(k int, category string) {
+	/**
+	* reindex - method keeps the index of breaks in line with the category an interval is
+	*			updated to, the caller must hold the lock
+	* @k: position of the interval
+	* @category: its new category
+	*/
+	wasBreak := r.intervals[k].Category != pomodoro.CategoryPomodoro
+	isBreak := category != pomodoro.CategoryPomodoro
+	if wasBreak == isBreak {
+		return
+	}
+
+	pos := sort.SearchInts(r.breaks, k)
+	if isBreak {
+		r.breaks = append(r.breaks, 0)
+		copy(r.breaks[pos+1:], r.breaks[pos:])
+		r.breaks[pos] = k
+		return
+	}
+	r.breaks = append(r.breaks[:pos], r.breaks[pos+1:]...)
+}
+
 func (r *inMemoryRepo) ByID(id int64)(pomodoro.Interval, error) {
 	/**
 	* ByID - method retrieve and return an item by its ID
@@ -72,29 +136,47 @@ func (r *inMemoryRepo) ByID(id int64)(pomodoro.Interval, error) {
 	r.RLock() // prevents concurrent access to the data store while making changes to it.
 	defer r.RUnlock()
 	i := pomodoro.Interval{}
-	if id == 0 {
+	if id <= 0 || id > int64(len(r.intervals)) {
 		return i, fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
 	}
 	
-	i = r.intervals[id-1]
+	i = clone(r.intervals[id-1])
 	return i, nil
 }
 
+func (r *inMemoryRepo) Last() (pomodoro.Interval, error) {
+	/**
+	* Last - method retrieves the most recently created interval that is not soft-deleted
+	* Return: last interval or pomodoro.ErrNoIntervals if the data store is empty
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	for k := len(r.intervals) - 1; k >= 0; k-- {
+		if !r.intervals[k].Deleted {
+			return clone(r.intervals[k]), nil
+		}
+	}
+
+	return pomodoro.Interval{}, pomodoro.ErrNoIntervals
+}
+
 func (r *inMemoryRepo) Breaks(n int) ([]pomodoro.Interval, error)  {
 	/**
-	* Breaks - method retrieves a given number n of the intervals of category break
+	* Breaks - method retrieves a given number n of the intervals of category break, most
+	*		   recent first, walking the index of breaks rather than the whole history
 	*
 	* @n: the value of the number to retrieve of category break
 	* Return: intervals or error if no data
 	*/
-	r.RLocker() // prevents concurrent access to the data store while making changes to it.
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
 	defer r.RUnlock()
 	data := []pomodoro.Interval{}
-	for k := len(r.intervals) - 1; k >= 0; k-- {
-		if r.intervals[k].Category == pomodoro.CategoryPomodoro {
+	for b := len(r.breaks) - 1; b >= 0; b-- {
+		i := r.intervals[r.breaks[b]]
+		if i.Deleted {
 			continue
 		}
-		data = append(data, r.intervals[k])
+		data = append(data, clone(i))
 		if len(data) == n{
 			return data, nil
 		}
@@ -102,3 +184,163 @@ func (r *inMemoryRepo) Breaks(n int) ([]pomodoro.Interval, error)  {
 	
 	return data, nil
 }
+
+func (r *inMemoryRepo) BreaksByCategory(category string, n int) ([]pomodoro.Interval, error) {
+	/**
+	* BreaksByCategory - method retrieves a given number n of the breaks of a category, most
+	*					 recent first, walking the index of breaks like Breaks
+	* @category: CategoryShortBreak or CategoryLongBreak
+	* @n: the number of breaks to retrieve, zero or less retrieves all
+	* Return: intervals, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for b := len(r.breaks) - 1; b >= 0; b-- {
+		i := r.intervals[r.breaks[b]]
+		if i.Category != category || i.Deleted {
+			continue
+		}
+		data = append(data, clone(i))
+		if len(data) == n {
+			return data, nil
+		}
+	}
+
+	return data, nil
+}
+
+func (r *inMemoryRepo) ByProject(name string) ([]pomodoro.Interval, error) {
+	/**
+	* ByProject - method retrieves the intervals of a project in creation order
+	*
+	* @name: the name of the project
+	* Return: intervals of the project, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for _, i := range r.intervals {
+		if i.Project != name || i.Deleted {
+			continue
+		}
+		data = append(data, clone(i))
+	}
+
+	return data, nil
+}
+
+func (r *inMemoryRepo) DeleteAll() error {
+	/**
+	* DeleteAll - method removes every interval by reinitializing the data store,
+	*			  IDs start from 1 again afterwards
+	*/
+	r.Lock() // prevents concurrent access to the data store while making changes to it.
+	defer r.Unlock()
+	r.intervals = []pomodoro.Interval{}
+	r.breaks = nil
+
+	return nil
+}
+
+func (r *inMemoryRepo) BySessionTag(tag string) ([]pomodoro.Interval, error) {
+	/**
+	* BySessionTag - method retrieves the intervals of a tagged session in creation order
+	*
+	* @tag: the tag of the session
+	* Return: intervals of the session, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for _, i := range r.intervals {
+		if i.SessionTag != tag || i.Deleted {
+			continue
+		}
+		data = append(data, clone(i))
+	}
+
+	return data, nil
+}
+
+func (r *inMemoryRepo) ByDurationRange(min, max time.Duration) ([]pomodoro.Interval, error) {
+	/**
+	* ByDurationRange - method retrieves the intervals whose ActualDuration is within [min, max]
+	*
+	* @min: the shortest duration to retrieve
+	* @max: the longest duration to retrieve
+	* Return: intervals in creation order, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for _, i := range r.intervals {
+		if i.ActualDuration < min || i.ActualDuration > max || i.Deleted {
+			continue
+		}
+		data = append(data, clone(i))
+	}
+
+	return data, nil
+}
+
+func (r *inMemoryRepo) ByTaskID(id string) ([]pomodoro.Interval, error) {
+	/**
+	* ByTaskID - method retrieves the intervals logged against an external task in creation order
+	*
+	* @id: the ID of the task
+	* Return: intervals of the task, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for _, i := range r.intervals {
+		if i.TaskID != id || i.Deleted {
+			continue
+		}
+		data = append(data, clone(i))
+	}
+
+	return data, nil
+}
+
+func (r *inMemoryRepo) All() ([]pomodoro.Interval, error) {
+	/**
+	* All - method retrieves every interval that is not soft-deleted in creation order
+	* Return: a copy of the intervals, changing it doesn't change the repository
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := make([]pomodoro.Interval, 0, len(r.intervals))
+	for _, i := range r.intervals {
+		if i.Deleted {
+			continue
+		}
+		data = append(data, clone(i))
+	}
+
+	return data, nil
+}
+
+func (r *inMemoryRepo) IntervalsBetween(start, end time.Time) ([]pomodoro.Interval, error) {
+	/**
+	* IntervalsBetween - method retrieves the intervals started within [start, end) in
+	*					 chronological order, e.g. for weekly reports
+	* @start: the earliest start time, inclusive
+	* @end: the latest start time, exclusive
+	* Return: intervals, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+	data := []pomodoro.Interval{}
+	for _, i := range r.intervals {
+		if i.Deleted || i.StartTime.Before(start) || !i.StartTime.Before(end) {
+			continue
+		}
+		data = append(data, clone(i))
+	}
+	// intervals can be created out of order, e.g. when importing
+	sort.SliceStable(data, func(a, b int) bool { return data[a].StartTime.Before(data[b].StartTime) })
+
+	return data, nil
+}
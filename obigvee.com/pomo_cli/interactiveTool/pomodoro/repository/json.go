@@ -0,0 +1,197 @@
+package repository
+
+/**
+* This module implements the Repository interface with a JSON file, portable across machines.
+* The intervals are kept in memory as a cache and the whole array is written to the file
+* after every change, through a temporary file renamed over it so a crash mid-write leaves
+* the previous version intact. The file holds a SHA-256 checksum of the intervals next to
+* them, checked on load to detect a file corrupted on disk or edited by hand.
+*/
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+type jsonRepo struct {
+	*inMemoryRepo            // cache serving the queries
+	mu            sync.Mutex // serializes the changes so the file is written in order
+	path          string
+}
+
+// jsonFile is the content of the file, the checksum is the one of the compact intervals
+type jsonFile struct {
+	Checksum  string          `json:"sha256"`
+	Intervals json.RawMessage `json:"intervals"`
+}
+
+func checksum(intervals []byte) string {
+	sum := sha256.Sum256(intervals)
+	return hex.EncodeToString(sum[:])
+}
+
+func (r *jsonRepo) load() ([]pomodoro.Interval, error) {
+	/**
+	* load - method reads the intervals saved in the file and verifies their checksum. Files
+	*		 written before checksums were added hold a bare array and are read as is.
+	* Return: intervals, empty when the file is missing or empty, or error when it can't
+	*		  be read or decoded, pomodoro.ErrCorruptedData on a checksum mismatch
+	*/
+	intervals := []pomodoro.Interval{}
+
+	data, err := os.ReadFile(r.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return intervals, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return intervals, nil
+	}
+	if data[0] == '[' {
+		return intervals, json.Unmarshal(data, &intervals)
+	}
+
+	f := jsonFile{}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", pomodoro.ErrCorruptedData, r.path, err)
+	}
+	compact := &bytes.Buffer{}
+	if err := json.Compact(compact, f.Intervals); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", pomodoro.ErrCorruptedData, r.path, err)
+	}
+	if sum := checksum(compact.Bytes()); sum != f.Checksum {
+		return nil, fmt.Errorf("%w: %s: checksum %s, expected %s", pomodoro.ErrCorruptedData,
+			r.path, sum, f.Checksum)
+	}
+
+	return intervals, json.Unmarshal(compact.Bytes(), &intervals)
+}
+
+func NewJSONRepo(path string) (*jsonRepo, error) {
+	/**
+	* NewJSONRepo - function loads the intervals saved in the JSON file, a missing or empty
+	*				file starts an empty history
+	* @path: path of the JSON file
+	* Return: instance of jsonRepo or error when the file can't be read or decoded,
+	*		  pomodoro.ErrCorruptedData when its checksum doesn't match
+	*/
+	r := &jsonRepo{inMemoryRepo: NewInMemoryRepo(), path: path}
+
+	intervals, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	r.intervals = intervals
+	r.rebuildIndex()
+
+	return r, nil
+}
+
+func (r *jsonRepo) Verify() error {
+	/**
+	* Verify - method checks the file against its checksum, e.g. before a backup, to detect
+	*		   corruption that happened since it was loaded
+	* Return: error, pomodoro.ErrCorruptedData on a checksum mismatch
+	*/
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, err := r.load()
+	return err
+}
+
+func (r *jsonRepo) flush() error {
+	/**
+	* flush - method writes every interval of the cache to the file atomically
+	* Return: error
+	*/
+	r.inMemoryRepo.RLock()
+	intervals, err := json.Marshal(r.intervals)
+	r.inMemoryRepo.RUnlock()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(jsonFile{Checksum: checksum(intervals), Intervals: intervals}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(r.path), "."+filepath.Base(r.path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), r.path)
+}
+
+// The queries are served by the embedded inMemoryRepo, the changes are flushed to the file
+
+func (r *jsonRepo) Create(i pomodoro.Interval) (int64, error) {
+	/**
+	* Create - method saves a new interval and writes the file
+	* Return: ID of the saved entry
+	*/
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	id, err := r.inMemoryRepo.Create(i)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, r.flush()
+}
+
+func (r *jsonRepo) Update(i pomodoro.Interval) error {
+	/**
+	* Update - method updates the values of an existing entry and writes the file
+	*/
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.inMemoryRepo.Update(i); err != nil {
+		return err
+	}
+
+	return r.flush()
+}
+
+func (r *jsonRepo) DeleteAll() error {
+	/**
+	* DeleteAll - method removes every interval and writes the empty history to the file
+	*/
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.inMemoryRepo.DeleteAll(); err != nil {
+		return err
+	}
+
+	return r.flush()
+}
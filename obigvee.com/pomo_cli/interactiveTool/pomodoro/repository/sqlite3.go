@@ -0,0 +1,364 @@
+package repository
+
+/**
+* This module implements the Repository interface with a SQLite database so the history of
+* intervals survives the process. Durations are stored as integer nanoseconds, the fields
+* that are never queried on are kept together as JSON in the extra column.
+*/
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+const (
+	createTableInterval string = `CREATE TABLE IF NOT EXISTS "interval" (
+		"id" INTEGER PRIMARY KEY,
+		"start_time" DATETIME NOT NULL,
+		"planned_duration" INTEGER DEFAULT 0,
+		"actual_duration" INTEGER DEFAULT 0,
+		"category" TEXT NOT NULL,
+		"state" INTEGER DEFAULT 1,
+		"project" TEXT DEFAULT '',
+		"session_tag" TEXT DEFAULT '',
+		"task_id" TEXT DEFAULT '',
+		"deleted" INTEGER DEFAULT 0,
+		"extra" TEXT DEFAULT '{}'
+	);`
+
+	selectInterval string = `SELECT id, start_time, planned_duration, actual_duration, category,
+		state, project, session_tag, task_id, deleted, extra FROM "interval"`
+)
+
+// extra holds the fields of an interval stored as JSON in the extra column
+type extra struct {
+	Tags           []string          `json:"tags,omitempty"`
+	Deep           bool              `json:"deep,omitempty"`
+	LockedBy       string            `json:"locked_by,omitempty"`
+	LockHeartbeat  time.Time         `json:"lock_heartbeat"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+	Interruptions  int               `json:"interruptions,omitempty"`
+	PausedDuration time.Duration     `json:"paused_duration,omitempty"`
+	PausedAt       time.Time         `json:"paused_at"`
+	Billable       bool              `json:"billable,omitempty"`
+}
+
+type dbRepo struct {
+	db           *sql.DB
+	sync.RWMutex // mutexes prevents concurrent access to data
+}
+
+func NewSQLiteRepo(dbfile string) (*dbRepo, error) {
+	/**
+	* NewSQLiteRepo - function opens the SQLite database file, creating it and the interval
+	*				  table on first run
+	* @dbfile: path of the database file
+	* Return: instance of dbRepo or error when the database can't be opened
+	*/
+	db, err := sql.Open("sqlite3", dbfile)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetConnMaxLifetime(30 * time.Minute)
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(createTableInterval); err != nil {
+		return nil, err
+	}
+
+	return &dbRepo{db: db}, nil
+}
+
+func (r *dbRepo) Close() error {
+	/**
+	* Close - method closes the database, the repository can't be used afterwards
+	*/
+	return r.db.Close()
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanInterval(row scanner) (pomodoro.Interval, error) {
+	/**
+	* scanInterval - function reads an interval from a row selected with selectInterval
+	* @row: the row, either *sql.Row or *sql.Rows
+	* Return: the interval or error
+	*/
+	i := pomodoro.Interval{}
+	var data string
+	if err := row.Scan(&i.ID, &i.StartTime, &i.PlannedDuration, &i.ActualDuration, &i.Category,
+		&i.State, &i.Project, &i.SessionTag, &i.TaskID, &i.Deleted, &data); err != nil {
+		return i, err
+	}
+
+	e := extra{}
+	if err := json.Unmarshal([]byte(data), &e); err != nil {
+		return i, err
+	}
+	i.Tags = e.Tags
+	i.Deep = e.Deep
+	i.LockedBy = e.LockedBy
+	i.LockHeartbeat = e.LockHeartbeat
+	i.Metadata = e.Metadata
+	i.Interruptions = e.Interruptions
+	i.PausedDuration = e.PausedDuration
+	i.PausedAt = e.PausedAt
+	i.Billable = e.Billable
+
+	return i, nil
+}
+
+func extraOf(i pomodoro.Interval) (string, error) {
+	/**
+	* extraOf - function encodes the fields of an interval kept in the extra column
+	* @i: the interval
+	* Return: the JSON document or error
+	*/
+	data, err := json.Marshal(extra{
+		Tags:           i.Tags,
+		Deep:           i.Deep,
+		LockedBy:       i.LockedBy,
+		LockHeartbeat:  i.LockHeartbeat,
+		Metadata:       i.Metadata,
+		Interruptions:  i.Interruptions,
+		PausedDuration: i.PausedDuration,
+		PausedAt:       i.PausedAt,
+		Billable:       i.Billable,
+	})
+
+	return string(data), err
+}
+
+func (r *dbRepo) query(where string, args ...interface{}) ([]pomodoro.Interval, error) {
+	/**
+	* query - method retrieves the intervals matching a condition
+	* @where: the rest of the statement after the select, e.g. WHERE and ORDER BY clauses
+	* @args: the values of the placeholders of where
+	* Return: intervals, empty if there's none
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+
+	rows, err := r.db.Query(selectInterval+" "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	data := []pomodoro.Interval{}
+	for rows.Next() {
+		i, err := scanInterval(rows)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, i)
+	}
+
+	return data, rows.Err()
+}
+
+// Implementation of all the methods of the Repository interface using dbRepo type
+
+func (r *dbRepo) Create(i pomodoro.Interval) (int64, error) {
+	/**
+	* Create - method saves a new interval to the database
+	* @i: the interval, its ID is ignored
+	* Return: ID of the saved entry
+	*/
+	data, err := extraOf(i)
+	if err != nil {
+		return 0, err
+	}
+
+	r.Lock() // prevents concurrent access to the data store while making changes to it.
+	defer r.Unlock()
+
+	res, err := r.db.Exec(`INSERT INTO "interval" (start_time, planned_duration, actual_duration,
+		category, state, project, session_tag, task_id, deleted, extra)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		i.StartTime, i.PlannedDuration, i.ActualDuration, i.Category, i.State, i.Project,
+		i.SessionTag, i.TaskID, i.Deleted, data)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *dbRepo) Update(i pomodoro.Interval) error {
+	/**
+	* Update - method updates the values of an existing entry in the database
+	* @i: the interval to save
+	* Return: error, pomodoro.ErrInvalidID when there's no interval with the ID
+	*/
+	data, err := extraOf(i)
+	if err != nil {
+		return err
+	}
+
+	r.Lock() // prevents concurrent access to the data store while making changes to it.
+	defer r.Unlock()
+
+	res, err := r.db.Exec(`UPDATE "interval" SET start_time=?, planned_duration=?,
+		actual_duration=?, category=?, state=?, project=?, session_tag=?, task_id=?, deleted=?,
+		extra=? WHERE id=?`,
+		i.StartTime, i.PlannedDuration, i.ActualDuration, i.Category, i.State, i.Project,
+		i.SessionTag, i.TaskID, i.Deleted, data, i.ID)
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, i.ID)
+	}
+
+	return nil
+}
+
+func (r *dbRepo) ByID(id int64) (pomodoro.Interval, error) {
+	/**
+	* ByID - method retrieve and return an item by its ID
+	* @id: id of data to retrieve
+	* Return: data by parsed id or pomodoro.ErrInvalidID if there's no such interval
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+
+	i, err := scanInterval(r.db.QueryRow(selectInterval+" WHERE id=?", id))
+	if err == sql.ErrNoRows {
+		return i, fmt.Errorf("%w: %d", pomodoro.ErrInvalidID, id)
+	}
+
+	return i, err
+}
+
+func (r *dbRepo) Last() (pomodoro.Interval, error) {
+	/**
+	* Last - method retrieves the most recently created interval that is not soft-deleted
+	* Return: last interval or pomodoro.ErrNoIntervals if the database is empty
+	*/
+	r.RLock() // prevents concurrent access to the data store while making changes to it.
+	defer r.RUnlock()
+
+	i, err := scanInterval(r.db.QueryRow(selectInterval + " WHERE deleted=0 ORDER BY id DESC LIMIT 1"))
+	if err == sql.ErrNoRows {
+		return i, pomodoro.ErrNoIntervals
+	}
+
+	return i, err
+}
+
+func (r *dbRepo) Breaks(n int) ([]pomodoro.Interval, error) {
+	/**
+	* Breaks - method retrieves a given number n of the intervals of category break,
+	*		   most recent first
+	* @n: the value of the number to retrieve of category break, zero or less retrieves all
+	* Return: intervals or error if no data
+	*/
+	if n <= 0 {
+		n = -1 // no limit
+	}
+
+	return r.query("WHERE category!=? AND deleted=0 ORDER BY id DESC LIMIT ?",
+		pomodoro.CategoryPomodoro, n)
+}
+
+func (r *dbRepo) ByProject(name string) ([]pomodoro.Interval, error) {
+	/**
+	* ByProject - method retrieves the intervals of a project in creation order
+	* @name: the name of the project
+	* Return: intervals of the project, empty if there's none
+	*/
+	return r.query("WHERE project=? AND deleted=0 ORDER BY id", name)
+}
+
+func (r *dbRepo) DeleteAll() error {
+	/**
+	* DeleteAll - method removes every interval, IDs start from 1 again afterwards
+	*/
+	r.Lock() // prevents concurrent access to the data store while making changes to it.
+	defer r.Unlock()
+
+	_, err := r.db.Exec(`DELETE FROM "interval"`)
+	return err
+}
+
+func (r *dbRepo) BySessionTag(tag string) ([]pomodoro.Interval, error) {
+	/**
+	* BySessionTag - method retrieves the intervals of a tagged session in creation order
+	* @tag: the tag of the session
+	* Return: intervals of the session, empty if there's none
+	*/
+	return r.query("WHERE session_tag=? AND deleted=0 ORDER BY id", tag)
+}
+
+func (r *dbRepo) ByDurationRange(min, max time.Duration) ([]pomodoro.Interval, error) {
+	/**
+	* ByDurationRange - method retrieves the intervals whose ActualDuration is within [min, max]
+	* @min: the shortest duration to retrieve
+	* @max: the longest duration to retrieve
+	* Return: intervals in creation order, empty if there's none
+	*/
+	return r.query("WHERE actual_duration BETWEEN ? AND ? AND deleted=0 ORDER BY id", min, max)
+}
+
+func (r *dbRepo) ByTaskID(id string) ([]pomodoro.Interval, error) {
+	/**
+	* ByTaskID - method retrieves the intervals logged against an external task in creation order
+	* @id: the ID of the task
+	* Return: intervals of the task, empty if there's none
+	*/
+	return r.query("WHERE task_id=? AND deleted=0 ORDER BY id", id)
+}
+
+func (r *dbRepo) All() ([]pomodoro.Interval, error) {
+	/**
+	* All - method retrieves every interval that is not soft-deleted in creation order
+	* Return: intervals, empty if there's none
+	*/
+	return r.query("WHERE deleted=0 ORDER BY id")
+}
+
+func (r *dbRepo) IntervalsBetween(start, end time.Time) ([]pomodoro.Interval, error) {
+	/**
+	* IntervalsBetween - method retrieves the intervals started within [start, end) in
+	*					 chronological order
+	* @start: the earliest start time, inclusive
+	* @end: the latest start time, exclusive
+	* Return: intervals, empty if there's none
+	*/
+	// the times are stored as text with their zone offset, julianday compares them as instants
+	// but only to the millisecond, so the query is widened and the bounds checked exactly here
+	data, err := r.query(`WHERE julianday(start_time) >= julianday(?) AND julianday(start_time) < julianday(?)
+		AND deleted=0 ORDER BY julianday(start_time), id`, start.Add(-time.Second), end.Add(time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	between := []pomodoro.Interval{}
+	for _, i := range data {
+		if !i.StartTime.Before(start) && i.StartTime.Before(end) {
+			between = append(between, i)
+		}
+	}
+
+	return between, nil
+}
@@ -0,0 +1,633 @@
+package pomodoro_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro/repository"
+)
+
+func TestByProject(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	for _, project := range []string{"A", "B", "A", "", "A"} {
+		if _, err := repo.Create(pomodoro.Interval{
+			Category:       pomodoro.CategoryPomodoro,
+			ActualDuration: time.Minute,
+			Project:        project,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		project string
+		expIDs  []int64
+	}{
+		{project: "A", expIDs: []int64{1, 3, 5}},
+		{project: "B", expIDs: []int64{2}},
+		{project: "C", expIDs: []int64{}},
+	}
+
+	// Execute tests for ByProject
+	for _, tc := range testCases {
+		t.Run(tc.project, func(t *testing.T) {
+			res, err := repo.ByProject(tc.project)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res) != len(tc.expIDs) {
+				t.Fatalf("Expected %d intervals, got %d.\n", len(tc.expIDs), len(res))
+			}
+			for k, i := range res {
+				if i.ID != tc.expIDs[k] || i.Project != tc.project {
+					t.Errorf("Expected interval %d of project %q, got %d of %q.\n",
+						tc.expIDs[k], tc.project, i.ID, i.Project)
+				}
+			}
+		})
+	}
+}
+
+func TestByDurationRange(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	for _, d := range []time.Duration{
+		10 * time.Minute, 25 * time.Minute, 30 * time.Minute, 45 * time.Minute, 0,
+	} {
+		if _, err := repo.Create(pomodoro.Interval{
+			Category:       pomodoro.CategoryPomodoro,
+			ActualDuration: d,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		min    time.Duration
+		max    time.Duration
+		expIDs []int64
+	}{
+		{name: "LongerThan30m", min: 30 * time.Minute, max: time.Hour, expIDs: []int64{3, 4}},
+		{name: "InclusiveBounds", min: 10 * time.Minute, max: 25 * time.Minute, expIDs: []int64{1, 2}},
+		{name: "Empty", min: 50 * time.Minute, max: time.Hour, expIDs: []int64{}},
+	}
+
+	// Execute tests for ByDurationRange
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := repo.ByDurationRange(tc.min, tc.max)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(res) != len(tc.expIDs) {
+				t.Fatalf("Expected %d intervals, got %d.\n", len(tc.expIDs), len(res))
+			}
+			for k, i := range res {
+				if i.ID != tc.expIDs[k] {
+					t.Errorf("Expected interval ID %d, got %d.\n", tc.expIDs[k], i.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestByTaskID(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	for _, task := range []string{"POMO-1", "POMO-2", "POMO-1", ""} {
+		i, err := pomodoro.NewIntervalOfCategory(config, pomodoro.CategoryPomodoro, task, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i.ActualDuration = 20 * time.Minute
+		i.State = pomodoro.StateDone
+		if err := repo.Update(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		task     string
+		expIDs   []int64
+		expFocus time.Duration
+	}{
+		{task: "POMO-1", expIDs: []int64{1, 3}, expFocus: 40 * time.Minute},
+		{task: "POMO-2", expIDs: []int64{2}, expFocus: 20 * time.Minute},
+		{task: "POMO-3", expIDs: []int64{}, expFocus: 0},
+	}
+
+	// Execute tests for ByTaskID
+	for _, tc := range testCases {
+		t.Run(tc.task, func(t *testing.T) {
+			res, err := repo.ByTaskID(tc.task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(res) != len(tc.expIDs) {
+				t.Fatalf("Expected %d intervals, got %d.\n", len(tc.expIDs), len(res))
+			}
+			for k, i := range res {
+				if i.ID != tc.expIDs[k] || i.TaskID != tc.task {
+					t.Errorf("Expected interval %d of task %q, got %d of %q.\n",
+						tc.expIDs[k], tc.task, i.ID, i.TaskID)
+				}
+			}
+
+			focus, err := config.TaskFocus(tc.task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if focus != tc.expFocus {
+				t.Errorf("Expected focus %q, got %q.\n", tc.expFocus, focus)
+			}
+		})
+	}
+}
+
+func TestBreaksConcurrent(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+		for k := 0; k < 100; k++ {
+			i := pomodoro.Interval{Category: pomodoro.CategoryShortBreak, State: pomodoro.StateRunning}
+			id, err := repo.Create(i)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			i.ID = id
+			i.State = pomodoro.StateDone
+			if err := repo.Update(i); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		for k := 0; k < 100; k++ {
+			if _, err := repo.Breaks(3); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+
+	breaks, err := repo.Breaks(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(breaks) != 100 {
+		t.Errorf("Expected 100 breaks, got %d.\n", len(breaks))
+	}
+}
+
+func TestByIDOutOfRange(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	for k := 0; k < 2; k++ {
+		if _, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, id := range []int64{9999, 3, 0, -1} {
+		i, err := repo.ByID(id)
+		if !errors.Is(err, pomodoro.ErrInvalidID) {
+			t.Errorf("Expected error %q for ID %d, got %q.\n", pomodoro.ErrInvalidID, id, err)
+		}
+		if i.ID != 0 {
+			t.Errorf("Expected empty interval for ID %d, got %d.\n", id, i.ID)
+		}
+	}
+}
+
+func TestUpdateOutOfRange(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	for k := 0; k < 2; k++ {
+		if _, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, id := range []int64{9999, 3, 0, -1} {
+		err := repo.Update(pomodoro.Interval{ID: id, Category: pomodoro.CategoryShortBreak})
+		if !errors.Is(err, pomodoro.ErrInvalidID) {
+			t.Errorf("Expected error %q for ID %d, got %q.\n", pomodoro.ErrInvalidID, id, err)
+		}
+	}
+
+	// the existing intervals are untouched
+	if breaks, err := repo.Breaks(0); err != nil {
+		t.Fatal(err)
+	} else if len(breaks) != 0 {
+		t.Errorf("Expected no breaks, got %v.\n", breaks)
+	}
+}
+
+func TestBreaksIndex(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	for _, category := range []string{
+		pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro, pomodoro.CategoryLongBreak,
+	} {
+		if _, err := repo.Create(pomodoro.Interval{Category: category}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// converting intervals must keep the breaks in line
+	for id, category := range map[int64]string{
+		3: pomodoro.CategoryShortBreak,
+		4: pomodoro.CategoryPomodoro,
+	} {
+		i, err := repo.ByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		i.Category = category
+		if err := repo.Update(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		n      int
+		expIDs []int64
+	}{
+		{name: "All", n: 0, expIDs: []int64{6, 3, 2}},
+		{name: "Limited", n: 2, expIDs: []int64{6, 3}},
+	}
+
+	// Execute tests for the index of breaks
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			breaks, err := repo.Breaks(tc.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(breaks) != len(tc.expIDs) {
+				t.Fatalf("Expected %d breaks, got %d.\n", len(tc.expIDs), len(breaks))
+			}
+			for k, b := range breaks {
+				if b.ID != tc.expIDs[k] {
+					t.Errorf("Expected break %d at %d, got %d.\n", tc.expIDs[k], k, b.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestBreaksByCategory(t *testing.T) {
+	repo := repository.NewInMemoryRepo()
+	for _, category := range []string{
+		pomodoro.CategoryLongBreak, pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryLongBreak, pomodoro.CategoryShortBreak, pomodoro.CategoryLongBreak,
+	} {
+		if _, err := repo.Create(pomodoro.Interval{Category: category}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// a long break converted to work leaves the index
+	if err := repo.Update(pomodoro.Interval{ID: 4, Category: pomodoro.CategoryPomodoro}); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		category string
+		n        int
+		expIDs   []int64
+	}{
+		{name: "LongAll", category: pomodoro.CategoryLongBreak, n: 0, expIDs: []int64{6, 1}},
+		{name: "LongLimited", category: pomodoro.CategoryLongBreak, n: 1, expIDs: []int64{6}},
+		{name: "Short", category: pomodoro.CategoryShortBreak, n: 0, expIDs: []int64{5, 2}},
+		{name: "Pomodoro", category: pomodoro.CategoryPomodoro, n: 0, expIDs: []int64{}},
+	}
+
+	// Execute tests for BreaksByCategory
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			breaks, err := repo.BreaksByCategory(tc.category, tc.n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(breaks) != len(tc.expIDs) {
+				t.Fatalf("Expected %d breaks, got %d.\n", len(tc.expIDs), len(breaks))
+			}
+			for k, b := range breaks {
+				if b.ID != tc.expIDs[k] {
+					t.Errorf("Expected break %d at %d, got %d.\n", tc.expIDs[k], k, b.ID)
+				}
+			}
+		})
+	}
+}
+
+// scanBreaks is the backward scan of the whole history Breaks did before the index,
+// the baseline of BenchmarkBreaks
+func scanBreaks(intervals []pomodoro.Interval, n int) []pomodoro.Interval {
+	data := []pomodoro.Interval{}
+	for k := len(intervals) - 1; k >= 0; k-- {
+		if intervals[k].Category == pomodoro.CategoryPomodoro {
+			continue
+		}
+		data = append(data, intervals[k])
+		if len(data) == n {
+			break
+		}
+	}
+
+	return data
+}
+
+func BenchmarkBreaks(b *testing.B) {
+	benchCases := []struct {
+		name  string
+		every int // a break every that many intervals
+		n     int
+	}{
+		// the most recent breaks are close, the scan stops early too
+		{name: "Recent", every: 100, n: 3},
+		// breaks are rare, e.g. mostly skipped, the scan walks most of the history
+		{name: "Sparse", every: 20000, n: 3},
+		{name: "All", every: 100, n: 0},
+	}
+
+	// Execute benchmarks for Breaks
+	for _, bc := range benchCases {
+		repo := repository.NewInMemoryRepo()
+		intervals := make([]pomodoro.Interval, 0, 100000)
+		for k := 0; k < 100000; k++ {
+			i := pomodoro.Interval{ID: int64(k + 1), Category: pomodoro.CategoryPomodoro}
+			if k%bc.every == 0 {
+				i.Category = pomodoro.CategoryShortBreak
+			}
+			if _, err := repo.Create(i); err != nil {
+				b.Fatal(err)
+			}
+			intervals = append(intervals, i)
+		}
+
+		b.Run(bc.name+"/Index", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				if _, err := repo.Breaks(bc.n); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+		b.Run(bc.name+"/Scan", func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				scanBreaks(intervals, bc.n)
+			}
+		})
+	}
+}
+
+func TestAll(t *testing.T) {
+	t.Run("Empty", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		data, err := repo.All()
+		if err != nil {
+			t.Fatalf("Expected no error, got %q.\n", err)
+		}
+		if data == nil || len(data) != 0 {
+			t.Errorf("Expected an empty slice, got %v.\n", data)
+		}
+	})
+
+	t.Run("Populated", func(t *testing.T) {
+		repo, cleanup := getRepo(t)
+		defer cleanup()
+
+		start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.UTC)
+		exp := []pomodoro.Interval{
+			{StartTime: start, PlannedDuration: 25 * time.Minute, ActualDuration: 25 * time.Minute,
+				Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone,
+				Tags: []string{"writing"}, Metadata: map[string]string{"ticket": "42"}},
+			{StartTime: start.Add(25 * time.Minute), PlannedDuration: 5 * time.Minute,
+				ActualDuration: 2 * time.Minute, Category: pomodoro.CategoryShortBreak,
+				State: pomodoro.StateCancelled},
+			{StartTime: start.Add(30 * time.Minute), PlannedDuration: 25 * time.Minute,
+				ActualDuration: 10 * time.Minute, Category: pomodoro.CategoryPomodoro,
+				State: pomodoro.StatePaused},
+		}
+		for _, i := range exp {
+			if _, err := repo.Create(i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// soft-deleted intervals are left out
+		if _, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro,
+			Deleted: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		data, err := repo.All()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(data) != len(exp) {
+			t.Fatalf("Expected %d intervals, got %d.\n", len(exp), len(data))
+		}
+		for k, i := range data {
+			if i.ID != int64(k+1) {
+				t.Errorf("Expected ID %d at %d, got %d.\n", k+1, k, i.ID)
+			}
+			if !i.StartTime.Equal(exp[k].StartTime) {
+				t.Errorf("Expected StartTime %s, got %s.\n", exp[k].StartTime, i.StartTime)
+			}
+			if i.PlannedDuration != exp[k].PlannedDuration || i.ActualDuration != exp[k].ActualDuration {
+				t.Errorf("Expected durations %q and %q, got %q and %q.\n",
+					exp[k].PlannedDuration, exp[k].ActualDuration, i.PlannedDuration, i.ActualDuration)
+			}
+			if i.Category != exp[k].Category || i.State != exp[k].State {
+				t.Errorf("Expected %s in state %d, got %s in state %d.\n",
+					exp[k].Category, exp[k].State, i.Category, i.State)
+			}
+		}
+
+		// the result is a copy, down to the tags and metadata, so is the interval saved
+		data[0].State = pomodoro.StateNotStarted
+		data[0].Tags[0] = "reading"
+		data[0].Metadata["ticket"] = "7"
+		exp[0].Tags[0] = "coding"
+		exp[0].Metadata["ticket"] = "8"
+		i, err := repo.ByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.State != pomodoro.StateDone {
+			t.Errorf("Expected repository state %d after changing the copy, got %d.\n",
+				pomodoro.StateDone, i.State)
+		}
+		if i.Tags[0] != "writing" || i.Metadata["ticket"] != "42" {
+			t.Errorf("Expected repository tags [writing] and ticket 42 after changing the copies, got %v and %v.\n",
+				i.Tags, i.Metadata)
+		}
+
+		// changing the interval retrieved doesn't change the repository either
+		i.Tags[0] = "reading"
+		i.Metadata["ticket"] = "7"
+		if i, err = repo.ByID(1); err != nil {
+			t.Fatal(err)
+		}
+		if i.Tags[0] != "writing" || i.Metadata["ticket"] != "42" {
+			t.Errorf("Expected repository tags [writing] and ticket 42 after changing ByID, got %v and %v.\n",
+				i.Tags, i.Metadata)
+		}
+	})
+}
+
+func TestDeleteAll(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	for k := 0; k < 3; k++ {
+		if _, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StateDone}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.DeleteAll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNoIntervals, err)
+	}
+	data, err := repo.All()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != 0 {
+		t.Errorf("Expected no intervals, got %d.\n", len(data))
+	}
+
+	id, err := repo.Create(pomodoro.Interval{Category: pomodoro.CategoryPomodoro})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != 1 {
+		t.Errorf("Expected ID 1 after deleting everything, got %d.\n", id)
+	}
+}
+
+func TestLast(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	if _, err := repo.Last(); !errors.Is(err, pomodoro.ErrNoIntervals) {
+		t.Errorf("Expected error %q on an empty repository, got %q.\n", pomodoro.ErrNoIntervals, err)
+	}
+
+	testCases := []struct {
+		name     string
+		interval pomodoro.Interval
+		expID    int64
+	}{
+		{name: "First", expID: 1,
+			interval: pomodoro.Interval{Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone}},
+		{name: "Second", expID: 2,
+			interval: pomodoro.Interval{Category: pomodoro.CategoryShortBreak, State: pomodoro.StateRunning}},
+		// soft-deleted intervals are skipped
+		{name: "Deleted", expID: 2,
+			interval: pomodoro.Interval{Category: pomodoro.CategoryPomodoro, Deleted: true}},
+	}
+
+	// Execute tests for Last
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := repo.Create(tc.interval); err != nil {
+				t.Fatal(err)
+			}
+
+			i, err := repo.Last()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.ID != tc.expID {
+				t.Errorf("Expected last interval %d, got %d.\n", tc.expID, i.ID)
+			}
+		})
+	}
+}
+
+func TestIntervalsBetween(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	// a zone other than the one of the bounds, the instants are compared
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	monday := time.Date(2023, time.May, 8, 0, 0, 0, 0, time.UTC)
+	at := func(day int, hour int) time.Time {
+		return monday.AddDate(0, 0, day).Add(time.Duration(hour) * time.Hour).In(zone)
+	}
+	pomo := func(start time.Time) pomodoro.Interval {
+		return pomodoro.Interval{StartTime: start, Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StateDone}
+	}
+
+	// created out of chronological order, e.g. imported later
+	addIntervals(t, repo,
+		pomo(at(-1, 23)), // the sunday before
+		pomo(at(2, 9)),
+		pomo(at(0, 0)), // the start is inclusive
+		pomo(at(6, 23)),
+		pomo(at(7, 0)), // the end is exclusive
+		pomo(at(1, 9)),
+	)
+	deleted := pomo(at(3, 9))
+	deleted.Deleted = true
+	addIntervals(t, repo, deleted)
+
+	data, err := repo.IntervalsBetween(monday, monday.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := []time.Time{at(0, 0), at(1, 9), at(2, 9), at(6, 23)}
+	if len(data) != len(exp) {
+		t.Fatalf("Expected %d intervals, got %d: %v.\n", len(exp), len(data), data)
+	}
+	for k := range exp {
+		if !data[k].StartTime.Equal(exp[k]) {
+			t.Errorf("Expected interval %d to start at %s, got %s.\n", k, exp[k], data[k].StartTime)
+		}
+	}
+
+	if data, err = repo.IntervalsBetween(monday.AddDate(0, 1, 0), monday.AddDate(0, 2, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if data == nil || len(data) != 0 {
+		t.Errorf("Expected an empty slice, got %v.\n", data)
+	}
+}
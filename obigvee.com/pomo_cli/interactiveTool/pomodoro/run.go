@@ -0,0 +1,89 @@
+package pomodoro
+
+/**
+* This module implements blocking ways to run intervals for simple command line tools: Run
+* runs the next interval without wiring a context and the callbacks of Start, Ctrl-C cancels
+* it instead of killing the process, and RunLoop chains the intervals of a work session.
+*/
+
+import (
+	"context"
+	"os"
+	"os/signal"
+)
+
+// notifyInterrupt relays the interrupt signals to c until the returned function is called,
+// tests replace it to send fake signals
+var notifyInterrupt = func(c chan<- os.Signal) func() {
+	signal.Notify(c, os.Interrupt)
+	return func() { signal.Stop(c) }
+}
+
+func Run(config *IntervalConfig, onTick func(Interval)) (Interval, error) {
+	/**
+	* Run - function runs the next interval, as returned by GetInterVal, until it is done,
+	*		paused elsewhere or cancelled by an interrupt signal (Ctrl-C)
+	* @config: instance of IntervalConfig
+	* @onTick: function called on every tick while the interval runs, it may be nil
+	* Return: the interval in its final state, StateCancelled after an interrupt, or error
+	*/
+	i, err := GetInterVal(config)
+	if err != nil {
+		return i, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	stop := notifyInterrupt(sig)
+	defer stop()
+	go func() {
+		select {
+		case <-sig:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	noop := func(Interval) {}
+	if onTick == nil {
+		onTick = noop
+	}
+	if err := i.Start(ctx, config, noop, onTick, noop, noop); err != nil {
+		return i, err
+	}
+
+	return config.repo.ByID(i.ID)
+}
+
+func RunLoop(ctx context.Context, config *IntervalConfig, start, periodic, end, paused Callback) error {
+	/**
+	* RunLoop - function runs the intervals of a continuous session one after the other, each
+	*			pomodoro and break starts as soon as the previous interval is done
+	* @ctx: instance of context.Context, cancelling it cancels the running interval and ends
+	*		the loop
+	* @config: instance of IntervalConfig
+	* @start, @periodic, @end, @paused: Callback functions passed to Start for every interval
+	* Return: error, nil once ctx is cancelled or an interval stops without completing, e.g.
+	*		  when it's paused or cancelled elsewhere
+	*/
+	for ctx.Err() == nil {
+		i, err := GetInterVal(config)
+		if err != nil {
+			return err
+		}
+		if err := i.Start(ctx, config, start, periodic, end, paused); err != nil {
+			return err
+		}
+
+		if i, err = config.repo.ByID(i.ID); err != nil {
+			return err
+		}
+		if i.State != StateDone {
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package pomodoro_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+// fakeInterrupt hands the channel Run listens on to the test
+func fakeInterrupt(t *testing.T) <-chan chan<- os.Signal {
+	t.Helper()
+
+	sigs := make(chan chan<- os.Signal, 1)
+	restore := pomodoro.SetNotifyInterrupt(func(c chan<- os.Signal) func() {
+		sigs <- c
+		return func() {}
+	})
+	t.Cleanup(restore)
+
+	return sigs
+}
+
+func TestRun(t *testing.T) {
+	testCases := []struct {
+		name      string
+		interrupt bool
+		expState  pomodoro.State
+		expActual time.Duration
+	}{
+		{name: "Done", interrupt: false, expState: pomodoro.StateDone, expActual: 2 * time.Second},
+		{name: "Interrupted", interrupt: true, expState: pomodoro.StateCancelled, expActual: time.Second},
+	}
+
+	// Execute tests for Run
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			sigs := fakeInterrupt(t)
+			clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+			config := pomodoro.NewConfig(repo, 2*time.Second, 0, 0)
+			config.Clock = clock
+
+			ticks := make(chan pomodoro.Interval, 2)
+			type result struct {
+				i   pomodoro.Interval
+				err error
+			}
+			resCh := make(chan result)
+			go func() {
+				i, err := pomodoro.Run(config, func(i pomodoro.Interval) { ticks <- i })
+				resCh <- result{i, err}
+			}()
+
+			sig := <-sigs
+			clock.Tick(t, time.Second)
+			<-ticks
+			if tc.interrupt {
+				sig <- os.Interrupt
+			} else {
+				clock.Tick(t, time.Second)
+			}
+
+			res := <-resCh
+			if res.err != nil {
+				t.Fatal(res.err)
+			}
+			if res.i.State != tc.expState {
+				t.Errorf("Expected state %d, got %d.\n", tc.expState, res.i.State)
+			}
+			if res.i.ActualDuration != tc.expActual {
+				t.Errorf("Expected ActualDuration %q, got %q.\n", tc.expActual, res.i.ActualDuration)
+			}
+			if res.i.Category != pomodoro.CategoryPomodoro {
+				t.Errorf("Expected %s, got %s.\n", pomodoro.CategoryPomodoro, res.i.Category)
+			}
+		})
+	}
+}
+
+func TestRunLoop(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	// the system clock with a small tick, the intervals last a few ticks
+	config := pomodoro.NewConfig(repo, 30*time.Millisecond, 20*time.Millisecond, 0)
+	config.TickInterval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ended := []pomodoro.Interval{}
+	noop := func(pomodoro.Interval) {}
+	end := func(i pomodoro.Interval) {
+		ended = append(ended, i)
+		if len(ended) == 2 {
+			cancel()
+		}
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- pomodoro.RunLoop(ctx, config, noop, noop, end, noop)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the loop to run two intervals and stop")
+	}
+
+	expCategories := []string{pomodoro.CategoryPomodoro, pomodoro.CategoryShortBreak}
+	for k, exp := range expCategories {
+		i, err := repo.ByID(int64(k + 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Category != exp || i.State != pomodoro.StateDone {
+			t.Errorf("Expected interval %d to be a done %s, got a %s in state %d.\n",
+				k+1, exp, i.Category, i.State)
+		}
+	}
+
+	// the loop ends once the interval it runs is cancelled
+	if last, err := repo.Last(); err != nil {
+		t.Fatal(err)
+	} else if last.ID > 2 && last.State == pomodoro.StateRunning {
+		t.Errorf("Expected interval %d to be stopped, got state %d.\n", last.ID, last.State)
+	}
+}
+
+func TestRunLoopStopsWhenPaused(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, time.Second, 0, 0)
+	config.TickInterval = 10 * time.Millisecond
+
+	noop := func(pomodoro.Interval) {}
+	pause := func(i pomodoro.Interval) {
+		if err := i.Pause(config); err != nil {
+			t.Error(err)
+		}
+	}
+
+	errCh := make(chan error)
+	go func() {
+		errCh <- pomodoro.RunLoop(context.Background(), config, noop, pause, noop, noop)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the loop to stop once the interval is paused")
+	}
+
+	i, err := repo.Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.ID != 1 || i.State != pomodoro.StatePaused {
+		t.Errorf("Expected interval 1 to stay paused, got interval %d in state %d.\n", i.ID, i.State)
+	}
+}
@@ -0,0 +1,44 @@
+//go:build sqlite3
+
+package pomodoro_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro/repository"
+)
+
+func getRepo(t *testing.T) (pomodoro.Repository, func()) {
+	t.Helper()
+
+	tf, err := os.CreateTemp("", "pomo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tf.Close()
+
+	dbRepo, err := repository.NewSQLiteRepo(tf.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dbRepo, func() {
+		dbRepo.Close()
+		os.Remove(tf.Name())
+	}
+}
+
+func TestSQLiteByIDNotFound(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	if _, err := repo.ByID(1); !errors.Is(err, pomodoro.ErrInvalidID) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidID, err)
+	}
+	if err := repo.Update(pomodoro.Interval{ID: 1}); !errors.Is(err, pomodoro.ErrInvalidID) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidID, err)
+	}
+}
@@ -0,0 +1,1179 @@
+package pomodoro
+
+/**
+* This module implements reporting helpers computed from the intervals saved in the repository.
+* The helpers only read from the repository, they never change the state of an interval.
+*/
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+func history(r Repository) ([]Interval, error) {
+	/**
+	* history - function retrieves every interval saved in the repository in creation order,
+	*			 skipping soft-deleted ones
+	* @r: instance of the Repository to read from
+	* Return: slice of intervals, empty when the repository has no data, or ErrInvalidState
+	*		  when an interval has an unknown state so it isn't counted wrongly
+	*/
+	intervals, err := r.All()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range intervals {
+		if !i.IsValidState() {
+			return nil, fmt.Errorf("%w: %d of interval %d", ErrInvalidState, i.State, i.ID)
+		}
+	}
+
+	return intervals, nil
+}
+
+func (config *IntervalConfig) in(t time.Time) time.Time {
+	/**
+	* in - method converts a time to the configured Location
+	* @t: the time to convert
+	* Return: the same instant in the Location, unchanged when there's none
+	*/
+	if config.Location == nil {
+		return t
+	}
+
+	return t.In(config.Location)
+}
+
+func (config *IntervalConfig) dayBounds(day time.Time) (time.Time, time.Time) {
+	/**
+	* dayBounds - method returns the start of the calendar day containing day and the start
+	*			  of the following one, in the configured Location or else the location of day.
+	*			  Days are 23 or 25 hours long across DST transitions.
+	* @day: any instant within the day
+	* Return: start (inclusive) and end (exclusive) of the day
+	*/
+	day = config.in(day)
+	y, m, d := day.Date()
+	start := time.Date(y, m, d, 0, 0, 0, 0, day.Location())
+
+	return start, start.AddDate(0, 0, 1)
+}
+
+func (config *IntervalConfig) InRange(start, end time.Time) ([]Interval, error) {
+	/**
+	* InRange - method retrieves the intervals started within [start, end). The bounds are
+	*			instants, they don't need to be aligned to days, e.g. the last 36 hours, and
+	*			intervals are attributed by their StartTime whatever the location.
+	* @start: start of the range
+	* @end: end of the range, excluded
+	* Return: intervals in creation order or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []Interval{}
+	for _, i := range intervals {
+		if i.StartTime.Before(start) || !i.StartTime.Before(end) {
+			continue
+		}
+		data = append(data, i)
+	}
+
+	return data, nil
+}
+
+func (config *IntervalConfig) UnbrokenFocus(now time.Time) (time.Duration, error) {
+	/**
+	* UnbrokenFocus - method sums the focus time of the pomodoros completed or in progress
+	*				  since the last break taken today. A break only interrupts the focus
+	*				  stretch if some of it was actually taken, cancelled pomodoros are skipped.
+	* @now: the current time, it determines what "today" is
+	* Return: the unbroken focus time or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return 0, err
+	}
+
+	today, _ := config.dayBounds(now)
+	var focus time.Duration
+
+	for k := len(intervals) - 1; k >= 0; k-- {
+		i := intervals[k]
+		if i.State == StateNotStarted {
+			continue
+		}
+		if i.StartTime.Before(today) {
+			break
+		}
+		if i.Category != CategoryPomodoro {
+			if i.ActualDuration > 0 {
+				break
+			}
+			continue
+		}
+		if i.State == StateCancelled {
+			continue
+		}
+		focus += i.ActualDuration
+	}
+
+	return focus, nil
+}
+
+func (config *IntervalConfig) FocusSince(sinceID int64) (time.Duration, error) {
+	/**
+	* FocusSince - method sums the focus time of the pomodoros completed after a given
+	*			   interval, e.g. the last break taken
+	* @sinceID: ID of the interval to count from, the interval itself is excluded
+	* Return: the focus time or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return 0, err
+	}
+
+	var focus time.Duration
+	for _, i := range intervals {
+		if i.ID > sinceID && config.completed(i) {
+			focus += i.ActualDuration
+		}
+	}
+
+	return focus, nil
+}
+
+func focusSpan(i Interval) (time.Time, time.Time) {
+	/**
+	* focusSpan - function returns the wall-clock span covered by an interval, from its
+	*			  start time until the time it has been running and paused for
+	* @i: the interval
+	* Return: start and end of the span
+	*/
+	return i.StartTime, i.StartTime.Add(i.ActualDuration + i.PausedDuration)
+}
+
+func (config *IntervalConfig) OverlappingEvent(start, end time.Time) ([]Interval, error) {
+	/**
+	* OverlappingEvent - method retrieves the pomodoros whose focus span intersects
+	*					 the event window [start, end)
+	* @start: start of the event window
+	* @end: end of the event window, excluded
+	* Return: pomodoros in creation order or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []Interval{}
+	for _, i := range intervals {
+		if i.Category != CategoryPomodoro || i.State == StateNotStarted {
+			continue
+		}
+
+		s, e := focusSpan(i)
+		if s.Before(end) && e.After(start) {
+			data = append(data, i)
+		}
+	}
+
+	return data, nil
+}
+
+func (config *IntervalConfig) FindOverlaps() ([][2]Interval, error) {
+	/**
+	* FindOverlaps - method finds the pairs of intervals whose focus spans overlap, e.g.
+	*				 duplicates left by an import. Intervals that never ran are skipped.
+	* Return: pairs ordered by start time, the earliest interval first in each pair, or
+	*		  error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := []Interval{}
+	for _, i := range intervals {
+		if i.ActualDuration > 0 {
+			spans = append(spans, i)
+		}
+	}
+	sort.SliceStable(spans, func(a, b int) bool {
+		return spans[a].StartTime.Before(spans[b].StartTime)
+	})
+
+	// only the intervals starting before the end of a span can overlap it
+	pairs := [][2]Interval{}
+	for a := range spans {
+		_, end := focusSpan(spans[a])
+		for b := a + 1; b < len(spans) && spans[b].StartTime.Before(end); b++ {
+			pairs = append(pairs, [2]Interval{spans[a], spans[b]})
+		}
+	}
+
+	return pairs, nil
+}
+
+// Summary holds the totals of the intervals started within a day
+type Summary struct {
+	Pomodoros int           `json:"pomodoros"` // number of completed pomodoros
+	Focus     time.Duration `json:"focus"`     // focus time of the completed pomodoros
+	Breaks    time.Duration `json:"breaks"`    // time spent on breaks
+}
+
+// DayStat holds the totals of a single calendar day
+type DayStat struct {
+	Date time.Time `json:"date"` // start of the day
+	Summary
+}
+
+func (config *IntervalConfig) tally(s *Summary, i Interval) {
+	/**
+	* tally - method adds an interval to the totals of a summary
+	* @s: the summary to update
+	* @i: the interval to add
+	*/
+	if config.completed(i) {
+		s.Pomodoros++
+		s.Focus += i.ActualDuration
+	}
+	if i.Category != CategoryPomodoro {
+		s.Breaks += i.ActualDuration
+	}
+}
+
+func inProject(i Interval, project []string) bool {
+	/**
+	* inProject - function checks an interval against an optional project filter
+	* @i: the interval to check
+	* @project: names of the projects to keep, an empty filter keeps every interval
+	* Return: true if the interval passes the filter
+	*/
+	if len(project) == 0 {
+		return true
+	}
+	for _, p := range project {
+		if i.Project == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (config *IntervalConfig) Summary(day time.Time, project ...string) (Summary, error) {
+	/**
+	* Summary - method totals the pomodoros and breaks of the day
+	* @day: any instant within the day to report on
+	* @project: optional names of the projects to report on
+	* Return: instance of Summary or error when there's an issue accessing the repository
+	*/
+	start, end := config.dayBounds(day)
+
+	return config.RangeSummary(start, end, project...)
+}
+
+func (config *IntervalConfig) RangeSummary(start, end time.Time, project ...string) (Summary, error) {
+	/**
+	* RangeSummary - method totals the pomodoros and breaks started within [start, end), a
+	*				 range not aligned to days, e.g. the last 36 hours
+	* @start: start of the range
+	* @end: end of the range, excluded
+	* @project: optional names of the projects to report on
+	* Return: instance of Summary or error when there's an issue accessing the repository
+	*/
+	s := Summary{}
+	intervals, err := config.InRange(start, end)
+	if err != nil {
+		return s, err
+	}
+
+	for _, i := range intervals {
+		if inProject(i, project) {
+			config.tally(&s, i)
+		}
+	}
+
+	return s, nil
+}
+
+func (config *IntervalConfig) BreakRatio(day time.Time) (float64, error) {
+	/**
+	* BreakRatio - method computes the time spent on breaks per completed focus time of the day
+	* @day: any instant within the day
+	* Return: the ratio, zero when there's no focus time, or error when there's an issue
+	*		  accessing the repository
+	*/
+	s, err := config.Summary(day)
+	if err != nil || s.Focus == 0 {
+		return 0, err
+	}
+
+	return float64(s.Breaks) / float64(s.Focus), nil
+}
+
+func (config *IntervalConfig) CheckBreakHealth(day time.Time) (bool, float64, error) {
+	/**
+	* CheckBreakHealth - method compares the break ratio of the day against MinBreakRatio.
+	*					 A day without focus time, or a config without MinBreakRatio, is healthy.
+	* @day: any instant within the day
+	* Return: whether the ratio is healthy, the ratio itself or error when there's an issue
+	*		  accessing the repository
+	*/
+	ratio, err := config.BreakRatio(day)
+	if err != nil {
+		return false, 0, err
+	}
+	if config.MinBreakRatio <= 0 || ratio >= config.MinBreakRatio {
+		return true, ratio, nil
+	}
+	if ratio > 0 {
+		return false, ratio, nil
+	}
+
+	// the ratio is also zero without focus time, only focus without breaks is unhealthy
+	s, err := config.Summary(day)
+	if err != nil {
+		return false, 0, err
+	}
+
+	return s.Focus == 0, 0, nil
+}
+
+func (config *IntervalConfig) GoalProgress(day time.Time, project ...string) (int, int, error) {
+	/**
+	* GoalProgress - method counts the pomodoros completed during the day against the DailyGoal
+	* @day: any instant within the day to report on
+	* @project: optional names of the projects to report on
+	* Return: number of completed pomodoros, number still needed to reach the goal and error
+	*		  when there's an issue accessing the repository
+	*/
+	intervals, err := config.InRange(config.dayBounds(day))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	done := 0
+	for _, i := range intervals {
+		if config.completed(i) && inProject(i, project) {
+			done++
+		}
+	}
+
+	remaining := config.DailyGoal - done
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return done, remaining, nil
+}
+
+func (config *IntervalConfig) FocusRate(start, end time.Time) (float64, error) {
+	/**
+	* FocusRate - method computes how densely a working window was used for focus, as the
+	*			  minutes of completed pomodoros per wall-clock hour of the window
+	* @start: start of the window
+	* @end: end of the window, excluded
+	* Return: focus minutes per hour or ErrInvalidRange if the window is empty
+	*/
+	elapsed := end.Sub(start)
+	if elapsed <= 0 {
+		return 0, fmt.Errorf("%w: %s to %s", ErrInvalidRange, start, end)
+	}
+
+	intervals, err := config.InRange(start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	var focus time.Duration
+	for _, i := range intervals {
+		if config.completed(i) {
+			focus += i.ActualDuration
+		}
+	}
+
+	return focus.Minutes() / elapsed.Hours(), nil
+}
+
+func (config *IntervalConfig) hourlyFocus() ([24]time.Duration, error) {
+	/**
+	* hourlyFocus - method builds a heatmap of the completed focus time accumulated over
+	*				the whole history for each hour of the day, in local time
+	* Return: focus time indexed by hour or error when there's an issue accessing the repository
+	*/
+	hours := [24]time.Duration{}
+	intervals, err := history(config.repo)
+	if err != nil {
+		return hours, err
+	}
+
+	for _, i := range intervals {
+		if config.completed(i) {
+			hours[config.in(i.StartTime.Local()).Hour()] += i.ActualDuration
+		}
+	}
+
+	return hours, nil
+}
+
+func (config *IntervalConfig) PeakHour() (int, time.Duration, error) {
+	/**
+	* PeakHour - method finds the hour of the day (0-23, local time) with the greatest
+	*			 accumulated completed focus time, ties return the earliest hour
+	* Return: the hour, its focus time and ErrNoIntervals when there's no completed focus
+	*/
+	hours, err := config.hourlyFocus()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	peak := 0
+	for h, focus := range hours {
+		if focus > hours[peak] {
+			peak = h
+		}
+	}
+
+	if hours[peak] == 0 {
+		return 0, 0, ErrNoIntervals
+	}
+
+	return peak, hours[peak], nil
+}
+
+func (config *IntervalConfig) BestWeekday() (time.Weekday, time.Duration, error) {
+	/**
+	* BestWeekday - method finds the day of the week (local time) with the greatest average
+	*				completed focus time. The focus of each weekday is divided by the number of
+	*				distinct days it was recorded on so frequent weekdays aren't favored,
+	*				ties return the earliest weekday starting on Sunday
+	* Return: the weekday, its average focus time and ErrNoIntervals when there's no
+	*		  completed focus
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	totals := [7]time.Duration{}
+	days := [7]map[string]bool{}
+	for _, i := range intervals {
+		if !config.completed(i) {
+			continue
+		}
+		start := config.in(i.StartTime.Local())
+		wd := start.Weekday()
+		totals[wd] += i.ActualDuration
+		if days[wd] == nil {
+			days[wd] = map[string]bool{}
+		}
+		days[wd][start.Format("2006-01-02")] = true
+	}
+
+	best, bestAvg := time.Sunday, time.Duration(0)
+	for wd := range totals {
+		if len(days[wd]) == 0 {
+			continue
+		}
+		if avg := totals[wd] / time.Duration(len(days[wd])); avg > bestAvg {
+			best, bestAvg = time.Weekday(wd), avg
+		}
+	}
+
+	if bestAvg == 0 {
+		return 0, 0, ErrNoIntervals
+	}
+
+	return best, bestAvg, nil
+}
+
+func (config *IntervalConfig) GoalReachable(now time.Time, endOfDay time.Time) (bool, int, error) {
+	/**
+	* GoalReachable - method checks whether the pomodoros still needed to reach today's goal
+	*				  fit before the end of the day, when run back to back with short breaks
+	* @now: the current time
+	* @endOfDay: the time the working day ends
+	* Return: true if the goal is reachable, number of pomodoros needed and error when
+	*		  there's an issue accessing the repository
+	*/
+	_, needed, err := config.GoalProgress(now)
+	if err != nil {
+		return false, 0, err
+	}
+	if needed == 0 {
+		return true, 0, nil
+	}
+
+	required := time.Duration(needed)*config.PomodoroDuration +
+		time.Duration(needed-1)*config.ShortBreakDuration
+
+	return !now.Add(required).After(endOfDay), needed, nil
+}
+
+func (config *IntervalConfig) DailyAggregates(start, end time.Time) ([]DayStat, error) {
+	/**
+	* DailyAggregates - method totals the intervals of each calendar day from the day of start
+	*					to the day of end, both included. Days without activity are reported
+	*					with zero totals.
+	* @start: any instant within the first day
+	* @end: any instant within the last day
+	* Return: one DayStat per day in chronological order or ErrInvalidRange if end is before start
+	*/
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidRange, start, end)
+	}
+
+	start = config.in(start)
+	first, _ := config.dayBounds(start)
+	_, last := config.dayBounds(end.In(start.Location()))
+
+	stats := []DayStat{}
+	index := map[string]int{}
+	for d := first; d.Before(last); d = d.AddDate(0, 0, 1) {
+		index[d.Format("2006-01-02")] = len(stats)
+		stats = append(stats, DayStat{Date: d})
+	}
+
+	intervals, err := config.InRange(first, last)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, i := range intervals {
+		k := index[i.StartTime.In(start.Location()).Format("2006-01-02")]
+		config.tally(&stats[k].Summary, i)
+	}
+
+	return stats, nil
+}
+
+func (config *IntervalConfig) GoalETA(now time.Time) (time.Time, error) {
+	/**
+	* GoalETA - method estimates when today's goal would be reached by starting a pomodoro now
+	*			and running full cycles continuously. Breaks between the remaining pomodoros
+	*			follow the same rotation as nextCategory, so a long break is included once
+	*			PomodorosBeforeLongBreak pomodoros are completed since the last one.
+	* @now: the current time
+	* Return: the estimated time or error when there's an issue accessing the repository
+	*/
+	_, needed, err := config.GoalProgress(now)
+	if err != nil {
+		return now, err
+	}
+
+	done, err := config.pomodorosSinceLongBreak()
+	if err != nil {
+		return now, err
+	}
+	n := config.pomodorosBeforeLongBreak()
+
+	eta := now
+	for k := 1; k <= needed; k++ {
+		eta = eta.Add(config.PomodoroDuration)
+		if k == needed {
+			break
+		}
+
+		done++
+		if done >= n && !config.SkipLongBreaks {
+			eta = eta.Add(config.LongBreakDuration)
+			done = 0
+			continue
+		}
+		eta = eta.Add(config.ShortBreakDuration)
+	}
+
+	return eta, nil
+}
+
+func (config *IntervalConfig) AverageBlockLength(day time.Time) (time.Duration, error) {
+	/**
+	* AverageBlockLength - method computes the mean length of the work blocks of the day.
+	*					   A block is a run of consecutive started pomodoros: it spans from
+	*					   the start of its first pomodoro to the start of the break that
+	*					   follows it or, when no break follows, to the end of the focus
+	*					   time of its last pomodoro. Breaks never started don't delimit blocks.
+	* @day: any instant within the day
+	* Return: the mean block length, 0 when there are no blocks, or error when there's an
+	*		  issue accessing the repository
+	*/
+	intervals, err := config.InRange(config.dayBounds(day))
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		total   time.Duration
+		blocks  int
+		inBlock bool
+		start   time.Time
+		last    Interval
+	)
+
+	for _, i := range intervals {
+		if i.State == StateNotStarted {
+			continue
+		}
+
+		if i.Category == CategoryPomodoro {
+			if !inBlock {
+				inBlock, start = true, i.StartTime
+			}
+			last = i
+			continue
+		}
+
+		if inBlock {
+			total += i.StartTime.Sub(start)
+			blocks++
+			inBlock = false
+		}
+	}
+
+	if inBlock {
+		_, end := focusSpan(last)
+		total += end.Sub(start)
+		blocks++
+	}
+
+	if blocks == 0 {
+		return 0, nil
+	}
+
+	return total / time.Duration(blocks), nil
+}
+
+func (config *IntervalConfig) streak(day time.Time) (int, error) {
+	/**
+	* streak - method counts the consecutive calendar days, ending with the day given, with at
+	*		   least one completed pomodoro
+	* @day: any instant within the last day of the streak
+	* Return: number of days or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return 0, err
+	}
+
+	day = config.in(day)
+	active := map[string]bool{}
+	for _, i := range intervals {
+		if config.completed(i) {
+			active[i.StartTime.In(day.Location()).Format("2006-01-02")] = true
+		}
+	}
+
+	n := 0
+	for d, _ := config.dayBounds(day); active[d.Format("2006-01-02")]; d = d.AddDate(0, 0, -1) {
+		n++
+	}
+
+	return n, nil
+}
+
+func (config *IntervalConfig) SummaryCard(day time.Time, w io.Writer) error {
+	/**
+	* SummaryCard - method writes a small ASCII card framed in a box with the pomodoros
+	*				completed during the day, their focus time and the current streak
+	* @day: any instant within the day
+	* @w: writer to render the card to
+	* Return: error
+	*/
+	day = config.in(day)
+	s, err := config.Summary(day)
+	if err != nil {
+		return err
+	}
+	streak, err := config.streak(day)
+	if err != nil {
+		return err
+	}
+
+	lines := []string{
+		fmt.Sprintf("Pomodoro %s", day.Format("2006-01-02")),
+		fmt.Sprintf("Pomodoros: %d", s.Pomodoros),
+		fmt.Sprintf("Focus:     %s", s.Focus),
+		fmt.Sprintf("Streak:    %d days", streak),
+	}
+
+	width := 0
+	for _, l := range lines {
+		if len(l) > width {
+			width = len(l)
+		}
+	}
+
+	border := "+" + strings.Repeat("-", width+2) + "+\n"
+	card := border
+	for _, l := range lines {
+		card += fmt.Sprintf("| %-*s |\n", width, l)
+	}
+	card += border
+
+	_, err = io.WriteString(w, card)
+	return err
+}
+
+func (config *IntervalConfig) PlannedDurationDistribution(category string) (map[time.Duration]int, error) {
+	/**
+	* PlannedDurationDistribution - method counts the intervals of a category by their planned
+	*								duration, surfacing the settings used over time
+	* @category: category of the intervals to count
+	* Return: number of intervals by planned duration or error when there's an issue
+	*		  accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	dist := map[time.Duration]int{}
+	for _, i := range intervals {
+		if i.Category == category {
+			dist[i.PlannedDuration]++
+		}
+	}
+
+	return dist, nil
+}
+
+func (config *IntervalConfig) LongBreaks(limit int) ([]Interval, error) {
+	/**
+	* LongBreaks - method retrieves the long breaks taken or scheduled, most recent first
+	* @limit: maximum number of long breaks to return, zero or less returns them all
+	* Return: the long breaks or error when there's an issue accessing the repository
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	data := []Interval{}
+	for k := len(intervals) - 1; k >= 0; k-- {
+		if limit > 0 && len(data) == limit {
+			break
+		}
+		if intervals[k].Category == CategoryLongBreak {
+			data = append(data, intervals[k])
+		}
+	}
+
+	return data, nil
+}
+
+func (config *IntervalConfig) PlannedVsActual(day time.Time) (time.Duration, time.Duration, error) {
+	/**
+	* PlannedVsActual - method totals the planned and the actual durations of the pomodoros
+	*					completed during the day, to reveal sessions cut short or overrun
+	* @day: any instant within the day
+	* Return: planned total, actual total or error when there's an issue accessing the repository
+	*/
+	intervals, err := config.InRange(config.dayBounds(day))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var planned, actual time.Duration
+	for _, i := range intervals {
+		if config.completed(i) {
+			planned += i.PlannedDuration
+			actual += i.ActualDuration
+		}
+	}
+
+	return planned, actual, nil
+}
+
+func (config *IntervalConfig) TaskFocus(taskID string) (time.Duration, error) {
+	/**
+	* TaskFocus - method sums the focus time of the pomodoros completed for an external task
+	* @taskID: the ID of the task
+	* Return: the focus time or error when there's an issue accessing the repository
+	*/
+	intervals, err := config.repo.ByTaskID(taskID)
+	if err != nil {
+		return 0, err
+	}
+
+	var focus time.Duration
+	for _, i := range intervals {
+		if config.completed(i) {
+			focus += i.ActualDuration
+		}
+	}
+
+	return focus, nil
+}
+
+func (config *IntervalConfig) Rolling7DayAverage(end time.Time) (time.Duration, error) {
+	/**
+	* Rolling7DayAverage - method computes the mean daily focus time over the 7 days ending
+	*					   with the day of end, days without activity count as zero
+	* @end: any instant within the last day
+	* Return: the average or error when there's an issue accessing the repository
+	*/
+	stats, err := config.DailyAggregates(end.AddDate(0, 0, -6), end)
+	if err != nil {
+		return 0, err
+	}
+
+	var focus time.Duration
+	for _, s := range stats {
+		focus += s.Focus
+	}
+
+	return focus / time.Duration(len(stats)), nil
+}
+
+func FormatHuman(d time.Duration) string {
+	/**
+	* FormatHuman - function formats a duration for people to read, e.g. "1h 5m" or "45s",
+	*				rounded to the second and omitting the zero units
+	* @d: the duration to format
+	* Return: the formatted duration, "0s" for zero or less
+	*/
+	d = d.Round(time.Second)
+	if d <= 0 {
+		return "0s"
+	}
+
+	h, m, s := d/time.Hour, d%time.Hour/time.Minute, d%time.Minute/time.Second
+	parts := []string{}
+	if h > 0 {
+		parts = append(parts, fmt.Sprintf("%dh", h))
+	}
+	if m > 0 {
+		parts = append(parts, fmt.Sprintf("%dm", m))
+	}
+	if s > 0 {
+		parts = append(parts, fmt.Sprintf("%ds", s))
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func (config *IntervalConfig) MarkdownReport(day time.Time, w io.Writer) error {
+	/**
+	* MarkdownReport - method writes a Markdown report of the day for journals: a heading with
+	*				   the date, a bullet per interval started with its time, category,
+	*				   duration and state, and a line with the totals
+	* @day: any instant within the day
+	* @w: writer to render the report to
+	* Return: error
+	*/
+	day = config.in(day)
+	intervals, err := config.InRange(config.dayBounds(day))
+	if err != nil {
+		return err
+	}
+	s, err := config.Summary(day)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "## %s\n\n", day.Format("2006-01-02"))
+	for _, i := range intervals {
+		if i.State == StateNotStarted {
+			continue
+		}
+		fmt.Fprintf(&b, "- %s %s, %s (%s)\n", i.StartTime.In(day.Location()).Format("15:04"),
+			i.Category, FormatHuman(i.ActualDuration), i.State)
+	}
+	if len(intervals) > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "**Total:** %d pomodoros, %s focus, %s breaks\n",
+		s.Pomodoros, FormatHuman(s.Focus), FormatHuman(s.Breaks))
+
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+func (config *IntervalConfig) CompletionRate(day time.Time) (float64, error) {
+	/**
+	* CompletionRate - method computes the share of the pomodoros started during the day that
+	*				   were completed rather than cancelled, those still in progress are ignored
+	* @day: any instant within the day
+	* Return: the rate in [0, 1], zero when none ended, or error when there's an issue
+	*		  accessing the repository
+	*/
+	intervals, err := config.InRange(config.dayBounds(day))
+	if err != nil {
+		return 0, err
+	}
+
+	completed, cancelled := 0, 0
+	for _, i := range intervals {
+		switch {
+		case config.completed(i):
+			completed++
+		case i.Category == CategoryPomodoro && i.State == StateCancelled:
+			cancelled++
+		}
+	}
+
+	if completed+cancelled == 0 {
+		return 0, nil
+	}
+
+	return float64(completed) / float64(completed+cancelled), nil
+}
+
+func (config *IntervalConfig) cycleSummary(longBreak Interval) (int, time.Duration, error) {
+	/**
+	* cycleSummary - method counts the pomodoros completed in the cycle ended by a long break,
+	*				 the cycle starts after the previous long break or at the start of the history
+	* @longBreak: the long break ending the cycle
+	* Return: the number of completed pomodoros and their focus time, or error
+	*/
+	intervals, err := history(config.repo)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	pomodoros, focus := 0, time.Duration(0)
+	for k := len(intervals) - 1; k >= 0; k-- {
+		i := intervals[k]
+		if i.ID >= longBreak.ID {
+			continue
+		}
+		if i.Category == CategoryLongBreak {
+			break
+		}
+		if config.completed(i) {
+			pomodoros++
+			focus += i.ActualDuration
+		}
+	}
+
+	return pomodoros, focus, nil
+}
+
+func CategorySummary(repo Repository) (map[string]int, error) {
+	/**
+	* CategorySummary - function counts the completed intervals of each category, cancelled,
+	*					running and not started intervals are left out
+	* @repo: instance of the Repository to read from
+	* Return: number of completed intervals by category, categories without any are absent
+	*/
+	intervals, err := history(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, i := range intervals {
+		if i.State == StateDone {
+			counts[i.Category]++
+		}
+	}
+
+	return counts, nil
+}
+
+func (config *IntervalConfig) InterruptionHistogram(day time.Time) (map[int]int, error) {
+	/**
+	* InterruptionHistogram - method counts the pomodoros started during the day by the number
+	*						  of interruptions they had, whatever state they ended in
+	* @day: any instant within the day
+	* Return: number of pomodoros by number of interruptions, counts without any pomodoro
+	*		  are absent, or error when there's an issue accessing the repository
+	*/
+	intervals, err := config.InRange(config.dayBounds(day))
+	if err != nil {
+		return nil, err
+	}
+
+	histogram := map[int]int{}
+	for _, i := range intervals {
+		if i.Category != CategoryPomodoro || i.State == StateNotStarted {
+			continue
+		}
+		histogram[i.Interruptions]++
+	}
+
+	return histogram, nil
+}
+
+func TotalFocusTime(repo Repository) (time.Duration, error) {
+	/**
+	* TotalFocusTime - function sums the actual duration of every completed pomodoro, breaks
+	*				   and pomodoros that didn't complete are left out
+	* @repo: instance of the Repository to read from
+	* Return: the focus time, zero when no pomodoro was completed
+	*/
+	intervals, err := history(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	var focus time.Duration
+	for _, i := range intervals {
+		if i.Category == CategoryPomodoro && i.State == StateDone {
+			focus += i.ActualDuration
+		}
+	}
+
+	return focus, nil
+}
+
+func TimeByTag(repo Repository) (map[string]time.Duration, error) {
+	/**
+	* TimeByTag - function sums the actual duration of the completed pomodoros per tag, a
+	*			  pomodoro with several tags counts towards each of them
+	* @repo: instance of the Repository to read from
+	* Return: the focus time of each tag, untagged pomodoros are left out
+	*/
+	intervals, err := history(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	byTag := map[string]time.Duration{}
+	for _, i := range intervals {
+		if i.Category != CategoryPomodoro || i.State != StateDone {
+			continue
+		}
+		for _, tag := range i.Tags {
+			byTag[tag] += i.ActualDuration
+		}
+	}
+
+	return byTag, nil
+}
+
+func (config *IntervalConfig) ScheduleDailyWrap(ctx context.Context, at time.Time, cb func(DayStat)) error {
+	/**
+	* ScheduleDailyWrap - method calls cb with the totals of the day every day at the time of
+	*					  day of at, in its location, until ctx is cancelled. The day reported is
+	*					  the one ending at the scheduled time, so a wrap at midnight reports the
+	*					  day just ended. Time is measured with the configured Clock.
+	* @ctx: instance of context.Context, cancelling it stops the schedule
+	* @at: any instant at the time of day to wrap up at, its date is ignored
+	* @cb: function receiving the totals of the day
+	* Return: nil once ctx is cancelled, or error when there's an issue accessing the repository
+	*/
+	clock := config.clock()
+	now := clock.Now().In(at.Location())
+
+	y, m, d := now.Date()
+	next := time.Date(y, m, d, at.Hour(), at.Minute(), at.Second(), at.Nanosecond(), at.Location())
+	for !next.After(now) {
+		y, m, d = next.Date()
+		next = time.Date(y, m, d+1, at.Hour(), at.Minute(), at.Second(), at.Nanosecond(), at.Location())
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-clock.After(next.Sub(now)):
+		}
+
+		day := next.Add(-time.Nanosecond)
+		s, err := config.Summary(day)
+		if err != nil {
+			return err
+		}
+		start, _ := config.dayBounds(day)
+		cb(DayStat{Date: start, Summary: s})
+
+		// the time of day is kept across DST changes, missed days are skipped
+		now = clock.Now().In(at.Location())
+		for !next.After(now) {
+			y, m, d = next.Date()
+			next = time.Date(y, m, d+1, at.Hour(), at.Minute(), at.Second(), at.Nanosecond(), at.Location())
+		}
+	}
+}
+
+func (config *IntervalConfig) FocusDebt(weekStart time.Time, target time.Duration) (time.Duration, error) {
+	/**
+	* FocusDebt - method compares the focus time of the completed pomodoros of the week so
+	*			  far against a weekly target pro-rated by the share of the week elapsed, as
+	*			  told by the clock of the config
+	* @weekStart: start of the week
+	* @target: focus time aimed for the whole week
+	* Return: the surplus of focus, negative when behind the target, or error when there's an
+	*		  issue accessing the repository
+	*/
+	now := config.clock().Now()
+	weekEnd := weekStart.AddDate(0, 0, 7)
+
+	elapsed := now.Sub(weekStart)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	if week := weekEnd.Sub(weekStart); elapsed > week {
+		elapsed = week
+	}
+	proRated := time.Duration(float64(target) * float64(elapsed) / float64(weekEnd.Sub(weekStart)))
+
+	s, err := config.RangeSummary(weekStart, weekStart.Add(elapsed))
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Focus - proRated, nil
+}
+
+func (config *IntervalConfig) BillableFocus(start, end time.Time) (time.Duration, error) {
+	/**
+	* BillableFocus - method sums the focus time of the billable pomodoros completed among
+	*				  those started within [start, end)
+	* @start: start of the range
+	* @end: end of the range, excluded
+	* Return: the billable focus time or error when there's an issue accessing the repository
+	*/
+	intervals, err := config.InRange(start, end)
+	if err != nil {
+		return 0, err
+	}
+
+	var focus time.Duration
+	for _, i := range intervals {
+		if i.Billable && config.completed(i) {
+			focus += i.ActualDuration
+		}
+	}
+
+	return focus, nil
+}
+
+func CurrentStreak(repo Repository) (int, error) {
+	/**
+	* CurrentStreak - function counts the consecutive calendar days, ending today, on which at
+	*				  least one pomodoro was completed. Intervals count on the day they started,
+	*				  in the local time zone.
+	* @repo: instance of the Repository to read from
+	* Return: number of days, zero when no pomodoro was completed today, or error when
+	*		  there's an issue accessing the repository
+	*/
+	config := &IntervalConfig{repo: repo}
+
+	return config.streak(config.clock().Now())
+}
+
+func CurrentStreakAt(repo Repository, now time.Time) (int, error) {
+	/**
+	* CurrentStreakAt - function counts the consecutive calendar days, ending with the day of
+	*					now, on which at least one pomodoro was completed
+	* @repo: instance of the Repository to read from
+	* @now: the current time, e.g. from the Clock of a config
+	* Return: number of days, zero when no pomodoro was completed that day, or error when
+	*		  there's an issue accessing the repository
+	*/
+	config := &IntervalConfig{repo: repo}
+
+	return config.streak(now)
+}
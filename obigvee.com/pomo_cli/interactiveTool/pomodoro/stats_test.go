@@ -0,0 +1,1570 @@
+package pomodoro_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func addIntervals(t *testing.T, repo pomodoro.Repository, intervals ...pomodoro.Interval) {
+	t.Helper()
+
+	for _, i := range intervals {
+		if _, err := repo.Create(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// pomoAt builds a pomodoro of the default 25 minutes, tests set the other fields they need
+func pomoAt(start time.Time, actual time.Duration, state pomodoro.State) pomodoro.Interval {
+	return pomodoro.Interval{
+		StartTime:       start,
+		PlannedDuration: 25 * time.Minute,
+		ActualDuration:  actual,
+		Category:        pomodoro.CategoryPomodoro,
+		State:           state,
+	}
+}
+
+func TestUnbrokenFocus(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 15, 0, 0, 0, time.Local)
+
+	brk := func(start time.Time, d time.Duration, state pomodoro.State) pomodoro.Interval {
+		return pomodoro.Interval{
+			StartTime:       start,
+			PlannedDuration: 5 * time.Minute,
+			ActualDuration:  d,
+			Category:        pomodoro.CategoryShortBreak,
+			State:           state,
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expFocus  time.Duration
+	}{
+		{name: "NoIntervals", expFocus: 0},
+		{name: "NoBreaks",
+			intervals: []pomodoro.Interval{
+				pomoAt(now.Add(-3*time.Hour), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(now.Add(-2*time.Hour), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(now.Add(-10*time.Minute), 10*time.Minute, pomodoro.StateRunning),
+			},
+			expFocus: 60 * time.Minute,
+		},
+		{name: "BreakInBetween",
+			intervals: []pomodoro.Interval{
+				pomoAt(now.Add(-3*time.Hour), 25*time.Minute, pomodoro.StateDone),
+				brk(now.Add(-2*time.Hour), 5*time.Minute, pomodoro.StateDone),
+				pomoAt(now.Add(-time.Hour), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(now.Add(-20*time.Minute), 5*time.Minute, pomodoro.StatePaused),
+			},
+			expFocus: 30 * time.Minute,
+		},
+		{name: "SkippedBreakAndCancelled",
+			intervals: []pomodoro.Interval{
+				pomoAt(now.Add(-3*time.Hour), 25*time.Minute, pomodoro.StateDone),
+				brk(now.Add(-2*time.Hour), 0, pomodoro.StateCancelled),
+				pomoAt(now.Add(-time.Hour), 12*time.Minute, pomodoro.StateCancelled),
+				pomoAt(now.Add(-30*time.Minute), 25*time.Minute, pomodoro.StateDone),
+			},
+			expFocus: 50 * time.Minute,
+		},
+		{name: "YesterdayIgnored",
+			intervals: []pomodoro.Interval{
+				pomoAt(now.AddDate(0, 0, -1), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(now.Add(-time.Hour), 25*time.Minute, pomodoro.StateDone),
+			},
+			expFocus: 25 * time.Minute,
+		},
+	}
+
+	// Execute tests for UnbrokenFocus
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			focus, err := config.UnbrokenFocus(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if focus != tc.expFocus {
+				t.Errorf("Expected unbroken focus %q, got %q.\n", tc.expFocus, focus)
+			}
+		})
+	}
+}
+
+func TestOverlappingEvent(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	start := time.Date(2023, time.May, 10, 10, 0, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+
+	addIntervals(t, repo,
+		pomoAt(start.Add(-time.Hour), 25*time.Minute, pomodoro.StateDone),      // 1: before the window
+		pomoAt(start.Add(-10*time.Minute), 25*time.Minute, pomodoro.StateDone), // 2: partially inside, at the start
+		pomoAt(start.Add(20*time.Minute), 25*time.Minute, pomodoro.StateDone),  // 3: fully inside
+		pomodoro.Interval{ // 4: break inside the window
+			StartTime:       start.Add(45 * time.Minute),
+			PlannedDuration: 5 * time.Minute,
+			ActualDuration:  5 * time.Minute,
+			Category:        pomodoro.CategoryShortBreak,
+			State:           pomodoro.StateDone,
+		},
+		pomoAt(start.Add(50*time.Minute), 25*time.Minute, pomodoro.StateDone), // 5: partially inside, at the end
+		pomoAt(start.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),      // 6: starts when the window ends
+		pomodoro.Interval{Category: pomodoro.CategoryPomodoro},                // 7: not started
+	)
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	res, err := config.OverlappingEvent(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expIDs := []int64{2, 3, 5}
+	if len(res) != len(expIDs) {
+		t.Fatalf("Expected %d intervals, got %d.\n", len(expIDs), len(res))
+	}
+
+	for k, i := range res {
+		if i.ID != expIDs[k] {
+			t.Errorf("Expected interval ID %d, got %d.\n", expIDs[k], i.ID)
+		}
+	}
+}
+
+func TestGoalProgress(t *testing.T) {
+	day := time.Date(2023, time.May, 10, 18, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name         string
+		predicate    func(pomodoro.Interval) bool
+		expDone      int
+		expRemaining int
+	}{
+		{name: "DefaultPredicate", expDone: 3, expRemaining: 1},
+		{name: "CustomPredicate",
+			predicate: func(i pomodoro.Interval) bool {
+				return i.State == pomodoro.StateDone &&
+					i.Category == pomodoro.CategoryPomodoro &&
+					i.ActualDuration >= 20*time.Minute
+			},
+			expDone: 2, expRemaining: 2},
+	}
+
+	// Execute tests for GoalProgress
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo,
+				pomoAt(day.Add(-24*time.Hour), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(day.Add(-5*time.Hour), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(day.Add(-4*time.Hour), 15*time.Minute, pomodoro.StateDone),
+				pomoAt(day.Add(-3*time.Hour), 22*time.Minute, pomodoro.StateCancelled),
+				pomoAt(day.Add(-2*time.Hour), 20*time.Minute, pomodoro.StateDone),
+				pomodoro.Interval{
+					StartTime:      day.Add(-time.Hour),
+					ActualDuration: 30 * time.Minute,
+					Category:       pomodoro.CategoryLongBreak,
+					State:          pomodoro.StateDone,
+				},
+			)
+
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			config.DailyGoal = 4
+			config.CompletionPredicate = tc.predicate
+
+			done, remaining, err := config.GoalProgress(day)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if done != tc.expDone {
+				t.Errorf("Expected %d completed pomodoros, got %d.\n", tc.expDone, done)
+			}
+			if remaining != tc.expRemaining {
+				t.Errorf("Expected %d remaining pomodoros, got %d.\n", tc.expRemaining, remaining)
+			}
+		})
+	}
+}
+
+func TestFocusRate(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	end := start.Add(4 * time.Hour)
+
+	addIntervals(t, repo,
+		pomoAt(start.Add(-time.Hour), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(start, 25*time.Minute, pomodoro.StateDone),
+		pomoAt(start.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(start.Add(2*time.Hour), 25*time.Minute, pomodoro.StateCancelled),
+		pomoAt(start.Add(3*time.Hour), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(start.Add(4*time.Hour), 25*time.Minute, pomodoro.StateDone),
+	)
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	t.Run("Rate", func(t *testing.T) {
+		rate, err := config.FocusRate(start, end)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// 3 pomodoros of 25 minutes over 4 hours
+		if exp := 75.0 / 4; rate != exp {
+			t.Errorf("Expected focus rate %f, got %f.\n", exp, rate)
+		}
+	})
+
+	t.Run("EmptyWindow", func(t *testing.T) {
+		if _, err := config.FocusRate(start, start); !errors.Is(err, pomodoro.ErrInvalidRange) {
+			t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidRange, err)
+		}
+	})
+}
+
+func TestSummaryByProject(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := time.Date(2023, time.May, 10, 18, 0, 0, 0, time.Local)
+	add := func(project, category string, d time.Duration) {
+		addIntervals(t, repo, pomodoro.Interval{
+			StartTime:      day.Add(-time.Hour),
+			ActualDuration: d,
+			Category:       category,
+			State:          pomodoro.StateDone,
+			Project:        project,
+		})
+	}
+
+	add("A", pomodoro.CategoryPomodoro, time.Hour)
+	add("A", pomodoro.CategoryShortBreak, 5*time.Minute)
+	add("A", pomodoro.CategoryPomodoro, 2*time.Hour)
+	add("B", pomodoro.CategoryPomodoro, 30*time.Minute)
+	add("B", pomodoro.CategoryLongBreak, 15*time.Minute)
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	config.DailyGoal = 3
+
+	testCases := []struct {
+		name         string
+		project      []string
+		expSummary   pomodoro.Summary
+		expRemaining int
+	}{
+		{name: "AllProjects",
+			expSummary: pomodoro.Summary{Pomodoros: 3,
+				Focus: 3*time.Hour + 30*time.Minute, Breaks: 20 * time.Minute},
+			expRemaining: 0},
+		{name: "ProjectA", project: []string{"A"},
+			expSummary: pomodoro.Summary{Pomodoros: 2,
+				Focus: 3 * time.Hour, Breaks: 5 * time.Minute},
+			expRemaining: 1},
+		{name: "ProjectB", project: []string{"B"},
+			expSummary: pomodoro.Summary{Pomodoros: 1,
+				Focus: 30 * time.Minute, Breaks: 15 * time.Minute},
+			expRemaining: 2},
+	}
+
+	// Execute tests for Summary and GoalProgress by project
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := config.Summary(day, tc.project...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s != tc.expSummary {
+				t.Errorf("Expected summary %+v, got %+v.\n", tc.expSummary, s)
+			}
+
+			_, remaining, err := config.GoalProgress(day, tc.project...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if remaining != tc.expRemaining {
+				t.Errorf("Expected %d remaining pomodoros, got %d.\n", tc.expRemaining, remaining)
+			}
+		})
+	}
+}
+
+func TestPeakHour(t *testing.T) {
+	at := func(hour int, d time.Duration) pomodoro.Interval {
+		return pomodoro.Interval{
+			StartTime:      time.Date(2023, time.May, 10, hour, 5, 0, 0, time.Local),
+			ActualDuration: d,
+			Category:       pomodoro.CategoryPomodoro,
+			State:          pomodoro.StateDone,
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expHour   int
+		expTotal  time.Duration
+		expError  error
+	}{
+		{name: "NoFocus", expError: pomodoro.ErrNoIntervals},
+		{name: "Concentrated",
+			intervals: []pomodoro.Interval{
+				at(9, 25*time.Minute), at(14, 25*time.Minute),
+				at(14, 25*time.Minute), at(14, 20*time.Minute), at(20, 25*time.Minute),
+			},
+			expHour: 14, expTotal: 70 * time.Minute},
+		{name: "Tie",
+			intervals: []pomodoro.Interval{
+				at(16, 25*time.Minute), at(8, 25*time.Minute),
+			},
+			expHour: 8, expTotal: 25 * time.Minute},
+	}
+
+	// Execute tests for PeakHour
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			hour, total, err := config.PeakHour()
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Expected error %v, got %v.\n", tc.expError, err)
+			}
+			if hour != tc.expHour || total != tc.expTotal {
+				t.Errorf("Expected peak hour %d with %q, got %d with %q.\n",
+					tc.expHour, tc.expTotal, hour, total)
+			}
+		})
+	}
+}
+
+func TestGoalReachable(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 16, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name         string
+		done         int
+		endOfDay     time.Time
+		expReachable bool
+		expNeeded    int
+	}{
+		{name: "GoalMet", done: 4, endOfDay: now,
+			expReachable: true, expNeeded: 0},
+		// 2 pomodoros and 1 short break take 55 minutes
+		{name: "Reachable", done: 2, endOfDay: now.Add(time.Hour),
+			expReachable: true, expNeeded: 2},
+		{name: "JustReachable", done: 2, endOfDay: now.Add(55 * time.Minute),
+			expReachable: true, expNeeded: 2},
+		{name: "Unreachable", done: 1, endOfDay: now.Add(time.Hour),
+			expReachable: false, expNeeded: 3},
+	}
+
+	// Execute tests for GoalReachable
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			for k := 0; k < tc.done; k++ {
+				addIntervals(t, repo, pomodoro.Interval{
+					StartTime:      now.Add(-time.Duration(k+1) * time.Hour),
+					ActualDuration: 25 * time.Minute,
+					Category:       pomodoro.CategoryPomodoro,
+					State:          pomodoro.StateDone,
+				})
+			}
+
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			config.DailyGoal = 4
+
+			reachable, needed, err := config.GoalReachable(now, tc.endOfDay)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reachable != tc.expReachable {
+				t.Errorf("Expected reachable %t, got %t.\n", tc.expReachable, reachable)
+			}
+			if needed != tc.expNeeded {
+				t.Errorf("Expected %d pomodoros needed, got %d.\n", tc.expNeeded, needed)
+			}
+		})
+	}
+}
+
+func TestDailyAggregates(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := func(d, hour int) time.Time {
+		return time.Date(2023, time.May, d, hour, 0, 0, 0, time.Local)
+	}
+	add := func(start time.Time, category string, d time.Duration) {
+		addIntervals(t, repo, pomodoro.Interval{
+			StartTime:      start,
+			ActualDuration: d,
+			Category:       category,
+			State:          pomodoro.StateDone,
+		})
+	}
+
+	add(day(9, 23), pomodoro.CategoryPomodoro, 25*time.Minute)
+	add(day(10, 9), pomodoro.CategoryPomodoro, 25*time.Minute)
+	add(day(10, 10), pomodoro.CategoryShortBreak, 5*time.Minute)
+	add(day(10, 11), pomodoro.CategoryPomodoro, 20*time.Minute)
+	add(day(12, 0), pomodoro.CategoryLongBreak, 15*time.Minute)
+	add(day(12, 23), pomodoro.CategoryPomodoro, 25*time.Minute)
+	add(day(13, 0), pomodoro.CategoryPomodoro, 25*time.Minute)
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	stats, err := config.DailyAggregates(day(10, 12), day(12, 8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []pomodoro.DayStat{
+		{Date: day(10, 0), Summary: pomodoro.Summary{Pomodoros: 2,
+			Focus: 45 * time.Minute, Breaks: 5 * time.Minute}},
+		{Date: day(11, 0)},
+		{Date: day(12, 0), Summary: pomodoro.Summary{Pomodoros: 1,
+			Focus: 25 * time.Minute, Breaks: 15 * time.Minute}},
+	}
+
+	if len(stats) != len(expected) {
+		t.Fatalf("Expected %d days, got %d.\n", len(expected), len(stats))
+	}
+	for k, exp := range expected {
+		if !stats[k].Date.Equal(exp.Date) || stats[k].Summary != exp.Summary {
+			t.Errorf("Expected day %+v, got %+v.\n", exp, stats[k])
+		}
+	}
+
+	if _, err := config.DailyAggregates(day(12, 0), day(10, 0)); !errors.Is(err, pomodoro.ErrInvalidRange) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrInvalidRange, err)
+	}
+}
+
+func TestGoalETA(t *testing.T) {
+	now := time.Date(2023, time.May, 10, 14, 0, 0, 0, time.Local)
+
+	pomo := pomodoro.Interval{
+		StartTime:      now.Add(-3 * time.Hour),
+		ActualDuration: 25 * time.Minute,
+		Category:       pomodoro.CategoryPomodoro,
+		State:          pomodoro.StateDone,
+	}
+	brk := func(category string) pomodoro.Interval {
+		return pomodoro.Interval{
+			StartTime:      now.Add(-3 * time.Hour),
+			ActualDuration: 5 * time.Minute,
+			Category:       category,
+			State:          pomodoro.StateDone,
+		}
+	}
+	short := brk(pomodoro.CategoryShortBreak)
+	long := brk(pomodoro.CategoryLongBreak)
+	yesterday := pomo
+	yesterday.StartTime = now.AddDate(0, 0, -1)
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expETA    time.Duration
+	}{
+		{name: "GoalMet",
+			intervals: []pomodoro.Interval{pomo, short, pomo, short, pomo, short, pomo},
+			expETA:    0},
+		// 4 pomodoros with 3 short breaks
+		{name: "NotStarted", expETA: 4*25*time.Minute + 3*5*time.Minute},
+		// 3 pomodoros, the first one ends the cycle started yesterday
+		{name: "CrossesLongBreak",
+			intervals: []pomodoro.Interval{yesterday, short, yesterday, short, pomo, short},
+			expETA:    3*25*time.Minute + 15*time.Minute + 5*time.Minute},
+		// breaks don't count towards the cycle, pomodoros do
+		{name: "SkippedBreaks",
+			intervals: []pomodoro.Interval{pomo, pomo, short, short, short},
+			expETA:    2*25*time.Minute + 5*time.Minute},
+		// 3 pomodoros with 2 short breaks, a new cycle started
+		{name: "AfterLongBreak",
+			intervals: []pomodoro.Interval{short, short, short, long, pomo, short},
+			expETA:    3*25*time.Minute + 2*5*time.Minute},
+	}
+
+	// Execute tests for GoalETA
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			config.DailyGoal = 4
+
+			eta, err := config.GoalETA(now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if exp := now.Add(tc.expETA); !eta.Equal(exp) {
+				t.Errorf("Expected ETA %s, got %s.\n", exp, eta)
+			}
+		})
+	}
+}
+
+func TestAverageBlockLength(t *testing.T) {
+	day := time.Date(2023, time.May, 10, 0, 0, 0, 0, time.Local)
+	at := func(hour, min int) time.Time {
+		return day.Add(time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute)
+	}
+	brk := func(start time.Time, state pomodoro.State) pomodoro.Interval {
+		return pomodoro.Interval{
+			StartTime:      start,
+			ActualDuration: 5 * time.Minute,
+			Category:       pomodoro.CategoryShortBreak,
+			State:          state,
+		}
+	}
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expAvg    time.Duration
+	}{
+		{name: "NoBlocks", expAvg: 0},
+		{name: "SinglePomodoro",
+			intervals: []pomodoro.Interval{pomoAt(at(9, 0), 25*time.Minute, pomodoro.StateDone)},
+			expAvg:    25 * time.Minute},
+		// blocks of 30m (9:00-9:30) and 60m (10:00-11:00, break not taken)
+		// and 25m (12:00-12:25, no break after it)
+		{name: "Sequence",
+			intervals: []pomodoro.Interval{
+				pomoAt(at(9, 0), 25*time.Minute, pomodoro.StateDone),
+				brk(at(9, 30), pomodoro.StateDone),
+				pomoAt(at(10, 0), 25*time.Minute, pomodoro.StateDone),
+				brk(at(10, 25), pomodoro.StateNotStarted),
+				pomoAt(at(10, 30), 25*time.Minute, pomodoro.StateDone),
+				brk(at(11, 0), pomodoro.StateCancelled),
+				pomoAt(at(12, 0), 25*time.Minute, pomodoro.StateDone),
+			},
+			expAvg: (30*time.Minute + 60*time.Minute + 25*time.Minute) / 3},
+	}
+
+	// Execute tests for AverageBlockLength
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			avg, err := config.AverageBlockLength(day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if avg != tc.expAvg {
+				t.Errorf("Expected average block %q, got %q.\n", tc.expAvg, avg)
+			}
+		})
+	}
+}
+
+func TestSummaryCard(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := time.Date(2023, time.May, 10, 18, 0, 0, 0, time.Local)
+	for _, offset := range []int{-3, -1, -1, 0, 0, 0} {
+		addIntervals(t, repo, pomodoro.Interval{
+			StartTime:      day.AddDate(0, 0, offset),
+			ActualDuration: 25 * time.Minute,
+			Category:       pomodoro.CategoryPomodoro,
+			State:          pomodoro.StateDone,
+		})
+	}
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	var out bytes.Buffer
+	if err := config.SummaryCard(day, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "+---------------------+\n" +
+		"| Pomodoro 2023-05-10 |\n" +
+		"| Pomodoros: 3        |\n" +
+		"| Focus:     1h15m0s  |\n" +
+		"| Streak:    2 days   |\n" +
+		"+---------------------+\n"
+
+	if out.String() != expected {
+		t.Errorf("Expected card:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestFocusSince(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	addIntervals(t, repo,
+		pomodoro.Interval{StartTime: start, ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		pomodoro.Interval{StartTime: start.Add(25 * time.Minute), ActualDuration: 5 * time.Minute,
+			Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+		pomodoro.Interval{StartTime: start.Add(30 * time.Minute), ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		pomodoro.Interval{StartTime: start.Add(55 * time.Minute), ActualDuration: 10 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateCancelled},
+		pomodoro.Interval{StartTime: start.Add(65 * time.Minute), ActualDuration: 23 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+	)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	testCases := []struct {
+		name     string
+		sinceID  int64
+		expFocus time.Duration
+	}{
+		{name: "All", sinceID: 0, expFocus: 73 * time.Minute},
+		{name: "SinceBreak", sinceID: 2, expFocus: 48 * time.Minute},
+		{name: "SinceLast", sinceID: 5, expFocus: 0},
+	}
+
+	// Execute tests for FocusSince
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			focus, err := config.FocusSince(tc.sinceID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if focus != tc.expFocus {
+				t.Errorf("Expected focus %q, got %q.\n", tc.expFocus, focus)
+			}
+		})
+	}
+}
+
+func TestBestWeekday(t *testing.T) {
+	on := func(day int, d time.Duration) pomodoro.Interval {
+		return pomodoro.Interval{
+			StartTime:      time.Date(2023, time.May, day, 10, 0, 0, 0, time.Local),
+			ActualDuration: d,
+			Category:       pomodoro.CategoryPomodoro,
+			State:          pomodoro.StateDone,
+		}
+	}
+
+	testCases := []struct {
+		name       string
+		intervals  []pomodoro.Interval
+		expWeekday time.Weekday
+		expAverage time.Duration
+		expError   error
+	}{
+		{name: "NoFocus", expError: pomodoro.ErrNoIntervals},
+		{name: "AverageOverTotal",
+			intervals: []pomodoro.Interval{
+				// three Mondays with more focus in total but less per day
+				on(1, 25*time.Minute), on(1, 25*time.Minute),
+				on(8, 25*time.Minute), on(8, 25*time.Minute),
+				on(15, 25*time.Minute), on(15, 25*time.Minute),
+				// a single Wednesday
+				on(10, 25*time.Minute), on(10, 25*time.Minute), on(10, 25*time.Minute),
+			},
+			expWeekday: time.Wednesday, expAverage: 75 * time.Minute},
+		{name: "Tie",
+			intervals: []pomodoro.Interval{
+				on(12, 25*time.Minute), on(9, 25*time.Minute),
+			},
+			expWeekday: time.Tuesday, expAverage: 25 * time.Minute},
+	}
+
+	// Execute tests for BestWeekday
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			wd, avg, err := config.BestWeekday()
+			if !errors.Is(err, tc.expError) {
+				t.Fatalf("Expected error %v, got %v.\n", tc.expError, err)
+			}
+			if wd != tc.expWeekday || avg != tc.expAverage {
+				t.Errorf("Expected best weekday %s with %q, got %s with %q.\n",
+					tc.expWeekday, tc.expAverage, wd, avg)
+			}
+		})
+	}
+}
+
+func TestPlannedDurationDistribution(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	planned := func(category string, d time.Duration) pomodoro.Interval {
+		return pomodoro.Interval{PlannedDuration: d, Category: category, State: pomodoro.StateDone}
+	}
+	addIntervals(t, repo,
+		planned(pomodoro.CategoryPomodoro, 25*time.Minute),
+		planned(pomodoro.CategoryShortBreak, 5*time.Minute),
+		planned(pomodoro.CategoryPomodoro, 25*time.Minute),
+		planned(pomodoro.CategoryPomodoro, 50*time.Minute),
+		planned(pomodoro.CategoryShortBreak, 10*time.Minute),
+		planned(pomodoro.CategoryPomodoro, 50*time.Minute),
+		planned(pomodoro.CategoryPomodoro, 50*time.Minute),
+	)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	testCases := []struct {
+		category string
+		expDist  map[time.Duration]int
+	}{
+		{category: pomodoro.CategoryPomodoro,
+			expDist: map[time.Duration]int{25 * time.Minute: 2, 50 * time.Minute: 3}},
+		{category: pomodoro.CategoryShortBreak,
+			expDist: map[time.Duration]int{5 * time.Minute: 1, 10 * time.Minute: 1}},
+		{category: pomodoro.CategoryLongBreak, expDist: map[time.Duration]int{}},
+	}
+
+	// Execute tests for PlannedDurationDistribution
+	for _, tc := range testCases {
+		t.Run(tc.category, func(t *testing.T) {
+			dist, err := config.PlannedDurationDistribution(tc.category)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(dist) != len(tc.expDist) {
+				t.Fatalf("Expected distribution %v, got %v.\n", tc.expDist, dist)
+			}
+			for d, n := range tc.expDist {
+				if dist[d] != n {
+					t.Errorf("Expected %d intervals of %q, got %d.\n", n, d, dist[d])
+				}
+			}
+		})
+	}
+}
+
+func TestLongBreaks(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	for k, category := range []string{
+		pomodoro.CategoryLongBreak, pomodoro.CategoryShortBreak, pomodoro.CategoryPomodoro,
+		pomodoro.CategoryLongBreak, pomodoro.CategoryShortBreak, pomodoro.CategoryLongBreak,
+		pomodoro.CategoryPomodoro,
+	} {
+		addIntervals(t, repo, pomodoro.Interval{
+			StartTime: start.Add(time.Duration(k) * time.Hour),
+			Category:  category,
+			State:     pomodoro.StateDone,
+		})
+	}
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	testCases := []struct {
+		name   string
+		limit  int
+		expIDs []int64
+	}{
+		{name: "All", limit: 0, expIDs: []int64{6, 4, 1}},
+		{name: "Limited", limit: 2, expIDs: []int64{6, 4}},
+		{name: "AboveCount", limit: 10, expIDs: []int64{6, 4, 1}},
+	}
+
+	// Execute tests for LongBreaks
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			breaks, err := config.LongBreaks(tc.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(breaks) != len(tc.expIDs) {
+				t.Fatalf("Expected %d long breaks, got %d.\n", len(tc.expIDs), len(breaks))
+			}
+			for k, b := range breaks {
+				if b.ID != tc.expIDs[k] {
+					t.Errorf("Expected long break %d at %d, got %d.\n", tc.expIDs[k], k, b.ID)
+				}
+				if b.Category != pomodoro.CategoryLongBreak {
+					t.Errorf("Expected category %q, got %q.\n", pomodoro.CategoryLongBreak, b.Category)
+				}
+			}
+		})
+	}
+}
+
+func TestCheckBreakHealth(t *testing.T) {
+	day := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	interval := func(category string, d time.Duration) pomodoro.Interval {
+		return pomodoro.Interval{StartTime: day, ActualDuration: d, Category: category,
+			State: pomodoro.StateDone}
+	}
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		minRatio  float64
+		expOK     bool
+		expRatio  float64
+	}{
+		{name: "NoFocus", minRatio: 0.2, expOK: true, expRatio: 0},
+		{name: "Healthy", minRatio: 0.2,
+			intervals: []pomodoro.Interval{
+				interval(pomodoro.CategoryPomodoro, 25*time.Minute),
+				interval(pomodoro.CategoryShortBreak, 5*time.Minute),
+				interval(pomodoro.CategoryPomodoro, 25*time.Minute),
+				interval(pomodoro.CategoryLongBreak, 15*time.Minute),
+			},
+			expOK: true, expRatio: 0.4},
+		{name: "Unhealthy", minRatio: 0.2,
+			intervals: []pomodoro.Interval{
+				interval(pomodoro.CategoryPomodoro, 25*time.Minute),
+				interval(pomodoro.CategoryPomodoro, 25*time.Minute),
+				interval(pomodoro.CategoryShortBreak, 5*time.Minute),
+			},
+			expOK: false, expRatio: 0.1},
+		{name: "NoBreaks", minRatio: 0.2,
+			intervals: []pomodoro.Interval{
+				interval(pomodoro.CategoryPomodoro, 25*time.Minute),
+			},
+			expOK: false, expRatio: 0},
+		{name: "Disabled", minRatio: 0,
+			intervals: []pomodoro.Interval{
+				interval(pomodoro.CategoryPomodoro, 25*time.Minute),
+			},
+			expOK: true, expRatio: 0},
+	}
+
+	// Execute tests for CheckBreakHealth
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			config.MinBreakRatio = tc.minRatio
+
+			ok, ratio, err := config.CheckBreakHealth(day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tc.expOK {
+				t.Errorf("Expected healthy %t, got %t.\n", tc.expOK, ok)
+			}
+			if ratio != tc.expRatio {
+				t.Errorf("Expected ratio %.2f, got %.2f.\n", tc.expRatio, ratio)
+			}
+		})
+	}
+}
+
+func TestPlannedVsActual(t *testing.T) {
+	day := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	addIntervals(t, repo,
+		pomoAt(day, 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.Add(time.Hour), 20*time.Minute, pomodoro.StateDone),
+		pomoAt(day.Add(2*time.Hour), 15*time.Minute, pomodoro.StateDone),
+		// not completed, or on another day
+		pomoAt(day.Add(3*time.Hour), 5*time.Minute, pomodoro.StateCancelled),
+		pomoAt(day.AddDate(0, 0, 1), 25*time.Minute, pomodoro.StateDone),
+	)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	planned, actual, err := config.PlannedVsActual(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if planned != 75*time.Minute {
+		t.Errorf("Expected planned %q, got %q.\n", 75*time.Minute, planned)
+	}
+	if actual != 60*time.Minute {
+		t.Errorf("Expected actual %q, got %q.\n", 60*time.Minute, actual)
+	}
+}
+
+func TestRolling7DayAverage(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	end := time.Date(2023, time.May, 10, 18, 0, 0, 0, time.Local)
+	morning := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	addIntervals(t, repo,
+		// outside of the window
+		pomoAt(morning.AddDate(0, 0, -7), 100*time.Minute, pomodoro.StateDone),
+		pomoAt(morning.AddDate(0, 0, -6), 50*time.Minute, pomodoro.StateDone),
+		pomoAt(morning.AddDate(0, 0, -6), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(morning.AddDate(0, 0, -3), 60*time.Minute, pomodoro.StateDone),
+		pomoAt(morning, 40*time.Minute, pomodoro.StateDone),
+		// after the end day
+		pomoAt(morning.AddDate(0, 0, 1), 100*time.Minute, pomodoro.StateDone),
+	)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	avg, err := config.Rolling7DayAverage(end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := 25 * time.Minute; avg != exp {
+		t.Errorf("Expected average %q, got %q.\n", exp, avg)
+	}
+}
+
+func TestFindOverlaps(t *testing.T) {
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expPairs  [][2]int64
+	}{
+		{name: "NoOverlaps",
+			intervals: []pomodoro.Interval{
+				pomoAt(start, 25*time.Minute, pomodoro.StateDone),
+				pomoAt(start.Add(25*time.Minute), 5*time.Minute, pomodoro.StateDone), // starts when the first ends
+				pomoAt(start.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),
+			},
+			expPairs: [][2]int64{}},
+		{name: "Overlaps",
+			intervals: []pomodoro.Interval{
+				pomoAt(start.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),      // 1
+				pomoAt(start, 25*time.Minute, pomodoro.StateDone),                     // 2
+				pomoAt(start.Add(10*time.Minute), 25*time.Minute, pomodoro.StateDone), // 3: overlaps 2
+				pomoAt(start.Add(70*time.Minute), 5*time.Minute, pomodoro.StateDone),  // 4: inside 1
+				pomoAt(start.Add(20*time.Minute), 0, pomodoro.StateDone),              // 5: never ran
+				pomoAt(start.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),      // 6: duplicate of 1
+			},
+			expPairs: [][2]int64{{2, 3}, {1, 6}, {1, 4}, {6, 4}}},
+	}
+
+	// Execute tests for FindOverlaps
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			pairs, err := config.FindOverlaps()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pairs) != len(tc.expPairs) {
+				t.Fatalf("Expected %d pairs, got %d.\n", len(tc.expPairs), len(pairs))
+			}
+			for k, p := range pairs {
+				if p[0].ID != tc.expPairs[k][0] || p[1].ID != tc.expPairs[k][1] {
+					t.Errorf("Expected pair %v, got [%d %d].\n", tc.expPairs[k], p[0].ID, p[1].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatHuman(t *testing.T) {
+	testCases := []struct {
+		d   time.Duration
+		exp string
+	}{
+		{d: 0, exp: "0s"},
+		{d: 45 * time.Second, exp: "45s"},
+		{d: 25 * time.Minute, exp: "25m"},
+		{d: time.Hour + 5*time.Minute, exp: "1h 5m"},
+		{d: 2*time.Hour + 30*time.Second, exp: "2h 30s"},
+		{d: 90*time.Second + 400*time.Millisecond, exp: "1m 30s"},
+	}
+
+	// Execute tests for FormatHuman
+	for _, tc := range testCases {
+		t.Run(tc.exp, func(t *testing.T) {
+			if s := pomodoro.FormatHuman(tc.d); s != tc.exp {
+				t.Errorf("Expected %q, got %q.\n", tc.exp, s)
+			}
+		})
+	}
+}
+
+func TestMarkdownReport(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	addIntervals(t, repo,
+		pomodoro.Interval{StartTime: day, ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+		pomodoro.Interval{StartTime: day.Add(25 * time.Minute), ActualDuration: 5 * time.Minute,
+			Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+		pomodoro.Interval{StartTime: day.Add(30 * time.Minute), ActualDuration: 12 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateCancelled},
+		// another day
+		pomodoro.Interval{StartTime: day.AddDate(0, 0, 1), ActualDuration: 25 * time.Minute,
+			Category: pomodoro.CategoryPomodoro, State: pomodoro.StateDone},
+	)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	var out bytes.Buffer
+	if err := config.MarkdownReport(day, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "## 2023-05-10\n\n" +
+		"- 09:00 Pomodoro, 25m (Done)\n" +
+		"- 09:25 ShortBreak, 5m (Done)\n" +
+		"- 09:30 Pomodoro, 12m (Cancelled)\n\n" +
+		"**Total:** 1 pomodoros, 25m focus, 5m breaks\n"
+
+	if out.String() != expected {
+		t.Errorf("Expected report:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestCompletionRate(t *testing.T) {
+	day := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expRate   float64
+	}{
+		{name: "NoneStarted", expRate: 0,
+			intervals: []pomodoro.Interval{pomoAt(day, 10*time.Minute, pomodoro.StateNotStarted)}},
+		{name: "AllCompleted", expRate: 1,
+			intervals: []pomodoro.Interval{
+				pomoAt(day, 10*time.Minute, pomodoro.StateDone),
+				pomoAt(day, 10*time.Minute, pomodoro.StateDone),
+			}},
+		{name: "Mixed", expRate: 0.75,
+			intervals: []pomodoro.Interval{
+				pomoAt(day, 10*time.Minute, pomodoro.StateDone),
+				pomoAt(day, 10*time.Minute, pomodoro.StateCancelled),
+				pomoAt(day, 10*time.Minute, pomodoro.StateDone),
+				pomoAt(day, 10*time.Minute, pomodoro.StateDone),
+				pomoAt(day, 10*time.Minute, pomodoro.StateRunning),
+				pomoAt(day, 10*time.Minute, pomodoro.StateNotStarted),
+				{StartTime: day, Category: pomodoro.CategoryShortBreak, State: pomodoro.StateCancelled},
+			}},
+		{name: "AllCancelled", expRate: 0,
+			intervals: []pomodoro.Interval{pomoAt(day, 10*time.Minute, pomodoro.StateCancelled)}},
+	}
+
+	// Execute tests for CompletionRate
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			rate, err := config.CompletionRate(day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rate != tc.expRate {
+				t.Errorf("Expected rate %.2f, got %.2f.\n", tc.expRate, rate)
+			}
+		})
+	}
+}
+
+func TestCategorySummary(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	counts, err := pomodoro.CategorySummary(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 0 {
+		t.Errorf("Expected no counts for an empty repository, got %v.\n", counts)
+	}
+
+	interval := func(category string, state pomodoro.State) pomodoro.Interval {
+		return pomodoro.Interval{Category: category, State: state}
+	}
+	addIntervals(t, repo,
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateDone),
+		interval(pomodoro.CategoryShortBreak, pomodoro.StateDone),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateDone),
+		interval(pomodoro.CategoryShortBreak, pomodoro.StateCancelled),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateCancelled),
+		interval(pomodoro.CategoryShortBreak, pomodoro.StateDone),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateDone),
+		interval(pomodoro.CategoryLongBreak, pomodoro.StateDone),
+		interval(pomodoro.CategoryLongBreak, pomodoro.StatePaused),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateRunning),
+	)
+
+	counts, err = pomodoro.CategorySummary(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[string]int{
+		pomodoro.CategoryPomodoro:   3,
+		pomodoro.CategoryShortBreak: 2,
+		pomodoro.CategoryLongBreak:  1,
+	}
+	if len(counts) != len(exp) {
+		t.Errorf("Expected counts %v, got %v.\n", exp, counts)
+	}
+	for category, n := range exp {
+		if counts[category] != n {
+			t.Errorf("Expected %d %s, got %d.\n", n, category, counts[category])
+		}
+	}
+}
+
+func TestInterruptionHistogram(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	pomos := []pomodoro.Interval{
+		pomoAt(day, 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.Add(30*time.Minute), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.Add(2*time.Hour), 10*time.Minute, pomodoro.StateCancelled),
+		pomoAt(day.Add(3*time.Hour), 10*time.Minute, pomodoro.StatePaused),
+		pomoAt(day.Add(4*time.Hour), 25*time.Minute, pomodoro.StateDone),
+		// other days are left out
+		pomoAt(day.AddDate(0, 0, -1), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.AddDate(0, 0, 1), 25*time.Minute, pomodoro.StateDone),
+	}
+	for k, n := range []int{0, 2, 0, 3, 2, 1, 5, 0} {
+		pomos[k].Interruptions = n
+	}
+	addIntervals(t, repo, pomos...)
+	// breaks are left out
+	addIntervals(t, repo, pomodoro.Interval{StartTime: day.Add(25 * time.Minute),
+		Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone, Interruptions: 1})
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	histogram, err := config.InterruptionHistogram(day)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exp := map[int]int{0: 2, 1: 1, 2: 2, 3: 1}
+	if len(histogram) != len(exp) {
+		t.Errorf("Expected histogram %v, got %v.\n", exp, histogram)
+	}
+	for n, count := range exp {
+		if histogram[n] != count {
+			t.Errorf("Expected %d pomodoros with %d interruptions, got %d.\n", count, n, histogram[n])
+		}
+	}
+
+	histogram, err = config.InterruptionHistogram(day.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(histogram) != 0 {
+		t.Errorf("Expected an empty histogram for a day without pomodoros, got %v.\n", histogram)
+	}
+}
+
+func TestTotalFocusTime(t *testing.T) {
+	interval := func(category string, state pomodoro.State, actual time.Duration) pomodoro.Interval {
+		return pomodoro.Interval{Category: category, State: state, ActualDuration: actual}
+	}
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expFocus  time.Duration
+	}{
+		{name: "Empty", expFocus: 0},
+		{name: "NoneCompleted", expFocus: 0,
+			intervals: []pomodoro.Interval{
+				interval(pomodoro.CategoryPomodoro, pomodoro.StateCancelled, 10*time.Minute),
+				interval(pomodoro.CategoryShortBreak, pomodoro.StateDone, 5*time.Minute),
+			}},
+		{name: "Mixed", expFocus: 3*time.Hour + 20*time.Minute,
+			intervals: []pomodoro.Interval{
+				interval(pomodoro.CategoryPomodoro, pomodoro.StateDone, 25*time.Minute),
+				interval(pomodoro.CategoryShortBreak, pomodoro.StateDone, 5*time.Minute),
+				interval(pomodoro.CategoryPomodoro, pomodoro.StateDone, 2*time.Hour),
+				interval(pomodoro.CategoryLongBreak, pomodoro.StateDone, 15*time.Minute),
+				interval(pomodoro.CategoryPomodoro, pomodoro.StateCancelled, 12*time.Minute),
+				interval(pomodoro.CategoryPomodoro, pomodoro.StateRunning, 7*time.Minute),
+				interval(pomodoro.CategoryPomodoro, pomodoro.StateDone, 55*time.Minute),
+			}},
+	}
+
+	// Execute tests for TotalFocusTime
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+
+			focus, err := pomodoro.TotalFocusTime(repo)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if focus != tc.expFocus {
+				t.Errorf("Expected focus %q, got %q.\n", tc.expFocus, focus)
+			}
+		})
+	}
+}
+
+func TestTimeByTag(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	interval := func(category string, state pomodoro.State, actual time.Duration, tags ...string) pomodoro.Interval {
+		return pomodoro.Interval{Category: category, State: state, ActualDuration: actual, Tags: tags}
+	}
+	addIntervals(t, repo,
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateDone, 25*time.Minute, "writing"),
+		interval(pomodoro.CategoryShortBreak, pomodoro.StateDone, 5*time.Minute, "writing"),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateDone, 20*time.Minute, "writing", "review"),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateCancelled, 10*time.Minute, "review"),
+		interval(pomodoro.CategoryPomodoro, pomodoro.StateDone, 25*time.Minute),
+	)
+
+	byTag, err := pomodoro.TimeByTag(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]time.Duration{"writing": 45 * time.Minute, "review": 20 * time.Minute}
+	if len(byTag) != len(expected) {
+		t.Errorf("Expected tags %v, got %v.\n", expected, byTag)
+	}
+	for tag, exp := range expected {
+		if byTag[tag] != exp {
+			t.Errorf("Expected %q for tag %q, got %q.\n", exp, tag, byTag[tag])
+		}
+	}
+}
+
+func TestScheduleDailyWrap(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	day := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	addIntervals(t, repo,
+		pomoAt(day, 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.Add(time.Hour), 25*time.Minute, pomodoro.StateDone),
+		pomoAt(day.AddDate(0, 0, 1), 25*time.Minute, pomodoro.StateDone),
+	)
+
+	clock := newFakeClock(day)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	config.Clock = clock
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wraps := make(chan pomodoro.DayStat)
+	errCh := make(chan error)
+	// only the time of day of at matters
+	at := time.Date(2000, time.January, 1, 21, 0, 0, 0, time.Local)
+	go func() {
+		errCh <- config.ScheduleDailyWrap(ctx, at, func(s pomodoro.DayStat) { wraps <- s })
+	}()
+
+	expWrap := func(date time.Time, pomodoros int) {
+		t.Helper()
+
+		select {
+		case s := <-wraps:
+			if !s.Date.Equal(date) {
+				t.Errorf("Expected wrap of %s, got %s.\n", date, s.Date)
+			}
+			if s.Pomodoros != pomodoros || s.Focus != time.Duration(pomodoros)*25*time.Minute {
+				t.Errorf("Expected %d pomodoros, got %+v.\n", pomodoros, s.Summary)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Wrap callback was not executed")
+		}
+	}
+
+	clock.waitTimer(t)
+	clock.Advance(11 * time.Hour)
+	select {
+	case s := <-wraps:
+		t.Fatalf("Expected no wrap before 21:00, got %+v.\n", s)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	clock.Advance(time.Hour)
+	expWrap(time.Date(2023, time.May, 10, 0, 0, 0, 0, time.Local), 2)
+
+	// the next wrap is scheduled for the following day
+	clock.waitTimer(t)
+	clock.Advance(24 * time.Hour)
+	expWrap(time.Date(2023, time.May, 11, 0, 0, 0, 0, time.Local), 1)
+
+	clock.waitTimer(t)
+	cancel()
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRangesAcrossDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Time zone database unavailable: %s", err)
+	}
+
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	// clocks go forward from 02:00 EST to 03:00 EDT on 2023-03-12
+	at := func(d, h, m int) time.Time { return time.Date(2023, time.March, d, h, m, 0, 0, ny) }
+	starts := []time.Time{
+		at(10, 22, 59), // 1: before the range
+		at(10, 23, 0),  // 2: start of the range
+		at(11, 0, 30),  // 3: after midnight
+		at(11, 22, 0),  // 4: 03:00 UTC on the 12th
+		at(12, 1, 30),  // 5: before the transition
+		at(12, 3, 30),  // 6: after the transition
+		at(12, 12, 0),  // 7: end of the range
+		at(12, 23, 30), // 8: last minutes of the short day
+	}
+	for _, start := range starts {
+		addIntervals(t, repo, pomodoro.Interval{StartTime: start, PlannedDuration: 25 * time.Minute,
+			ActualDuration: 25 * time.Minute, Category: pomodoro.CategoryPomodoro,
+			State: pomodoro.StateDone})
+	}
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	config.Location = ny
+
+	// the last 36 hours, 37 on the wall clock because of the transition
+	end := at(12, 12, 0)
+	start := end.Add(-36 * time.Hour)
+	if exp := at(10, 23, 0); !start.Equal(exp) {
+		t.Fatalf("Expected the range to start at %s, got %s.\n", exp, start)
+	}
+
+	intervals, err := config.InRange(start.UTC(), end.UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expIDs := []int64{2, 3, 4, 5, 6}
+	if len(intervals) != len(expIDs) {
+		t.Fatalf("Expected %d intervals in range, got %d.\n", len(expIDs), len(intervals))
+	}
+	for k, i := range intervals {
+		if i.ID != expIDs[k] {
+			t.Errorf("Expected interval %d at %d, got %d.\n", expIDs[k], k, i.ID)
+		}
+	}
+
+	s, err := config.RangeSummary(start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Pomodoros != len(expIDs) || s.Focus != time.Duration(len(expIDs))*25*time.Minute {
+		t.Errorf("Expected %d pomodoros in range, got %+v.\n", len(expIDs), s)
+	}
+
+	// days are those of the Location, whatever the location of the times given
+	stats, err := config.DailyAggregates(at(11, 12, 0).UTC(), at(12, 12, 0).UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expDays := []struct {
+		date      time.Time
+		pomodoros int
+	}{
+		{date: at(11, 0, 0), pomodoros: 2},
+		{date: at(12, 0, 0), pomodoros: 4},
+	}
+	if len(stats) != len(expDays) {
+		t.Fatalf("Expected %d days, got %d.\n", len(expDays), len(stats))
+	}
+	for k, exp := range expDays {
+		if !stats[k].Date.Equal(exp.date) {
+			t.Errorf("Expected day %s, got %s.\n", exp.date, stats[k].Date)
+		}
+		if stats[k].Pomodoros != exp.pomodoros {
+			t.Errorf("Expected %d pomodoros on %s, got %d.\n",
+				exp.pomodoros, exp.date.Format("2006-01-02"), stats[k].Pomodoros)
+		}
+	}
+
+	// the day of the transition is 23 hours long
+	s, err = config.Summary(at(12, 12, 0).UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Pomodoros != 4 {
+		t.Errorf("Expected 4 pomodoros on the day of the transition, got %d.\n", s.Pomodoros)
+	}
+}
+
+func TestFocusDebt(t *testing.T) {
+	// Monday, the clock is on Thursday at noon: half of the week elapsed
+	weekStart := time.Date(2023, time.May, 8, 0, 0, 0, 0, time.UTC)
+	now := weekStart.Add(3*24*time.Hour + 12*time.Hour)
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expDebt   time.Duration
+	}{
+		{name: "Ahead", expDebt: time.Hour,
+			intervals: []pomodoro.Interval{
+				pomoAt(weekStart.Add(9*time.Hour), 3*time.Hour, pomodoro.StateDone),
+				pomoAt(weekStart.AddDate(0, 0, 1), 3*time.Hour, pomodoro.StateDone),
+				pomoAt(weekStart.AddDate(0, 0, 3), 2*time.Hour, pomodoro.StateDone),
+			}},
+		{name: "Behind", expDebt: -2 * time.Hour,
+			intervals: []pomodoro.Interval{
+				pomoAt(weekStart.Add(9*time.Hour), 3*time.Hour, pomodoro.StateDone),
+				pomoAt(weekStart.AddDate(0, 0, 2), 2*time.Hour, pomodoro.StateDone),
+				// not completed, before the week and not yet
+				{StartTime: weekStart.AddDate(0, 0, 1), ActualDuration: time.Hour,
+					Category: pomodoro.CategoryPomodoro, State: pomodoro.StateCancelled},
+				pomoAt(weekStart.AddDate(0, 0, -1), 4*time.Hour, pomodoro.StateDone),
+				pomoAt(now.Add(time.Hour), 4*time.Hour, pomodoro.StateDone),
+			}},
+		{name: "NoFocus", expDebt: -7 * time.Hour},
+	}
+
+	// Execute tests for FocusDebt
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+			config.Clock = newFakeClock(now)
+
+			debt, err := config.FocusDebt(weekStart, 14*time.Hour)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if debt != tc.expDebt {
+				t.Errorf("Expected debt %q, got %q.\n", tc.expDebt, debt)
+			}
+		})
+	}
+}
+
+func TestBillableFocus(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	start := time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local)
+	interval := func(offset time.Duration, category string, state pomodoro.State, billable bool,
+		actual time.Duration) pomodoro.Interval {
+		return pomodoro.Interval{StartTime: start.Add(offset), ActualDuration: actual,
+			Category: category, State: state, Billable: billable}
+	}
+	addIntervals(t, repo,
+		interval(0, pomodoro.CategoryPomodoro, pomodoro.StateDone, true, 25*time.Minute),
+		interval(30*time.Minute, pomodoro.CategoryPomodoro, pomodoro.StateDone, false, 25*time.Minute),
+		interval(time.Hour, pomodoro.CategoryPomodoro, pomodoro.StateDone, true, 20*time.Minute),
+		interval(90*time.Minute, pomodoro.CategoryPomodoro, pomodoro.StateCancelled, true, 10*time.Minute),
+		interval(2*time.Hour, pomodoro.CategoryShortBreak, pomodoro.StateDone, true, 5*time.Minute),
+		// out of the range
+		interval(-time.Hour, pomodoro.CategoryPomodoro, pomodoro.StateDone, true, 25*time.Minute),
+		interval(4*time.Hour, pomodoro.CategoryPomodoro, pomodoro.StateDone, true, 25*time.Minute),
+	)
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+	focus, err := config.BillableFocus(start, start.Add(4*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := 45 * time.Minute; focus != exp {
+		t.Errorf("Expected billable focus %q, got %q.\n", exp, focus)
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	noon := time.Date(2023, time.May, 10, 12, 0, 0, 0, time.Local)
+
+	testCases := []struct {
+		name      string
+		intervals []pomodoro.Interval
+		expStreak int
+	}{
+		{name: "Empty", expStreak: 0},
+		{name: "Today", expStreak: 1,
+			intervals: []pomodoro.Interval{pomoAt(noon, 25*time.Minute, pomodoro.StateDone)}},
+		// the gap two days ago ends the streak
+		{name: "Gap", expStreak: 2,
+			intervals: []pomodoro.Interval{
+				pomoAt(noon.AddDate(0, 0, -5), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon.AddDate(0, 0, -4), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon.AddDate(0, 0, -3), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon.AddDate(0, 0, -2), 25*time.Minute, pomodoro.StateCancelled),
+				pomoAt(noon.AddDate(0, 0, -1), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon, 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon, 25*time.Minute, pomodoro.StateDone),
+			}},
+		{name: "NothingToday", expStreak: 0,
+			intervals: []pomodoro.Interval{
+				pomoAt(noon.AddDate(0, 0, -2), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon.AddDate(0, 0, -1), 25*time.Minute, pomodoro.StateDone),
+				pomoAt(noon, 25*time.Minute, pomodoro.StateRunning),
+			}},
+		// completed breaks don't count
+		{name: "OnlyBreaks", expStreak: 0,
+			intervals: []pomodoro.Interval{
+				{StartTime: noon, Category: pomodoro.CategoryShortBreak, State: pomodoro.StateDone},
+			}},
+	}
+
+	// Execute tests for CurrentStreak
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			addIntervals(t, repo, tc.intervals...)
+
+			streak, err := pomodoro.CurrentStreakAt(repo, noon)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if streak != tc.expStreak {
+				t.Errorf("Expected streak of %d days, got %d.\n", tc.expStreak, streak)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package pomodoro
+
+/**
+* This module derives what the user is currently doing from the last interval, so UIs don't
+* have to combine categories and states themselves.
+*/
+
+import (
+	"fmt"
+)
+
+// StatusKind is the activity derived from the category and state of the last interval
+type StatusKind int
+
+const (
+	StatusIdle StatusKind = iota
+	StatusFocusing
+	StatusShortBreak
+	StatusLongBreak
+	StatusPaused
+)
+
+func (config *IntervalConfig) Status() (StatusKind, Interval, error) {
+	/**
+	* Status - method reports the current activity: a running interval gives its category,
+	*		   a paused one StatusPaused, anything else (no interval, not started, done or
+	*		   cancelled) StatusIdle
+	* Return: the status, the last interval the status is derived from (zero value when the
+	*		  repository is empty) or error when there's an issue accessing the repository,
+	*		  ErrInvalidState when the last interval has an unknown state
+	*/
+	i, err := config.repo.Last()
+	if err == ErrNoIntervals {
+		return StatusIdle, Interval{}, nil
+	}
+	if err != nil {
+		return StatusIdle, Interval{}, err
+	}
+
+	switch i.State {
+	case StatePaused:
+		return StatusPaused, i, nil
+	case StateRunning:
+		switch i.Category {
+		case CategoryShortBreak:
+			return StatusShortBreak, i, nil
+		case CategoryLongBreak:
+			return StatusLongBreak, i, nil
+		default:
+			return StatusFocusing, i, nil
+		}
+	case StateNotStarted, StateDone, StateCancelled, StatePendingConfirm:
+		return StatusIdle, i, nil
+	default:
+		return StatusIdle, i, fmt.Errorf("%w: %d", ErrInvalidState, i.State)
+	}
+}
@@ -0,0 +1,64 @@
+package pomodoro_test
+
+import (
+	"testing"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestStatus(t *testing.T) {
+	testCases := []struct {
+		name      string
+		category  string
+		state     pomodoro.State
+		empty     bool
+		expStatus pomodoro.StatusKind
+	}{
+		{name: "Empty", empty: true, expStatus: pomodoro.StatusIdle},
+		{name: "NotStarted", category: pomodoro.CategoryPomodoro,
+			state: pomodoro.StateNotStarted, expStatus: pomodoro.StatusIdle},
+		{name: "Focusing", category: pomodoro.CategoryPomodoro,
+			state: pomodoro.StateRunning, expStatus: pomodoro.StatusFocusing},
+		{name: "ShortBreak", category: pomodoro.CategoryShortBreak,
+			state: pomodoro.StateRunning, expStatus: pomodoro.StatusShortBreak},
+		{name: "LongBreak", category: pomodoro.CategoryLongBreak,
+			state: pomodoro.StateRunning, expStatus: pomodoro.StatusLongBreak},
+		{name: "PausedPomodoro", category: pomodoro.CategoryPomodoro,
+			state: pomodoro.StatePaused, expStatus: pomodoro.StatusPaused},
+		{name: "PausedBreak", category: pomodoro.CategoryShortBreak,
+			state: pomodoro.StatePaused, expStatus: pomodoro.StatusPaused},
+		{name: "Done", category: pomodoro.CategoryPomodoro,
+			state: pomodoro.StateDone, expStatus: pomodoro.StatusIdle},
+		{name: "Cancelled", category: pomodoro.CategoryLongBreak,
+			state: pomodoro.StateCancelled, expStatus: pomodoro.StatusIdle},
+	}
+
+	// Execute tests for Status
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, cleanup := getRepo(t)
+			defer cleanup()
+
+			var id int64
+			if !tc.empty {
+				var err error
+				id, err = repo.Create(pomodoro.Interval{Category: tc.category, State: tc.state})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			config := pomodoro.NewConfig(repo, 0, 0, 0)
+
+			status, i, err := config.Status()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tc.expStatus {
+				t.Errorf("Expected status %d, got %d.\n", tc.expStatus, status)
+			}
+			if i.ID != id {
+				t.Errorf("Expected interval %d, got %d.\n", id, i.ID)
+			}
+		})
+	}
+}
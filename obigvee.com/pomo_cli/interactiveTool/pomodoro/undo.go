@@ -0,0 +1,109 @@
+package pomodoro
+
+/**
+* This module keeps the versions of the intervals before the changes made by the callers,
+* e.g. Pause or SoftDelete, so the last ones can be undone. The progress saved by a running
+* timer isn't recorded.
+*/
+
+import (
+	"sync"
+)
+
+// number of changes that can be undone, older ones are forgotten
+const undoLimit = 32
+
+// undoStack holds the previous versions of the changed intervals, the most recent last
+type undoStack struct {
+	mu        sync.Mutex
+	snapshots []Interval
+}
+
+func (s *undoStack) push(i Interval) {
+	/**
+	* push - method records the version of an interval before a change, dropping the oldest
+	*		 one when the stack is full
+	* @i: the interval before the change
+	*/
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.snapshots) == undoLimit {
+		s.snapshots = append(s.snapshots[:0], s.snapshots[1:]...)
+	}
+	s.snapshots = append(s.snapshots, i)
+}
+
+func (s *undoStack) pop() (Interval, bool) {
+	/**
+	* pop - method removes the most recent version recorded
+	* Return: the interval before the change and false when the stack is empty
+	*/
+	if s == nil {
+		return Interval{}, false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.snapshots) == 0 {
+		return Interval{}, false
+	}
+	i := s.snapshots[len(s.snapshots)-1]
+	s.snapshots = s.snapshots[:len(s.snapshots)-1]
+
+	return i, true
+}
+
+func (config *IntervalConfig) update(i Interval) error {
+	/**
+	* update - method saves a change made by a caller, recording the previous version of the
+	*		   interval so Undo can revert it
+	* @i: the changed interval
+	* Return: error
+	*/
+	prev, err := config.repo.ByID(i.ID)
+	if err != nil {
+		return err
+	}
+	if err := config.repo.Update(i); err != nil {
+		return err
+	}
+	config.undo.push(prev)
+
+	return nil
+}
+
+func (config *IntervalConfig) Undo() error {
+	/**
+	* Undo - method reverts the last change made by Pause, Cancel, Complete, ConvertCategory,
+	*		 SetMeta, SoftDelete or Restore, saving the interval as it was before it. The
+	*		 time it ran is kept and a timer isn't started or stopped, so an interval
+	*		 restored to running without a timer ticking it is saved as paused for Start
+	*		 to resume it.
+	* Return: error, ErrNothingToUndo when there's no change left to revert
+	*/
+	prev, ok := config.undo.pop()
+	if !ok {
+		return ErrNothingToUndo
+	}
+
+	// hold off the timer of the interval, if any, so the undo and a tick don't overwrite
+	// each other
+	latest, release, err := config.hold(prev)
+	defer release()
+	if err != nil {
+		return err
+	}
+
+	prev.ActualDuration = latest.ActualDuration
+	ticking := latest.State == StateRunning && config.inFlight.find(prev.ID) != nil
+	if prev.State == StateRunning && !ticking {
+		prev.State = StatePaused
+		prev.PausedAt = config.clock().Now()
+	}
+
+	return config.repo.Update(prev)
+}
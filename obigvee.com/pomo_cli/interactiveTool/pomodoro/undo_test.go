@@ -0,0 +1,164 @@
+package pomodoro_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"obigvee.com/pomo_cli/interactiveTool/pomo/obigvee.com/pomo_cli/interactiveTool/pomodoro"
+)
+
+func TestUndo(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	if err := config.Undo(); !errors.Is(err, pomodoro.ErrNothingToUndo) {
+		t.Errorf("Expected error %q, got %q.\n", pomodoro.ErrNothingToUndo, err)
+	}
+
+	addIntervals(t, repo, pomodoro.Interval{Category: pomodoro.CategoryPomodoro,
+		State: pomodoro.StateRunning})
+	i, err := repo.ByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := i.SetMeta(config, "ticket", "42"); err != nil {
+		t.Fatal(err)
+	}
+	if i, err = repo.ByID(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := i.Pause(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SoftDelete(i.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// the changes are reverted most recent first, no timer ticks the interval so it's
+	// restored paused rather than running
+	expected := []struct {
+		deleted bool
+		state   pomodoro.State
+		meta    string
+	}{
+		{deleted: false, state: pomodoro.StatePaused, meta: "42"},
+		{deleted: false, state: pomodoro.StatePaused, meta: "42"},
+		{deleted: false, state: pomodoro.StatePaused, meta: ""},
+	}
+	for k, exp := range expected {
+		if err := config.Undo(); err != nil {
+			t.Fatal(err)
+		}
+		i, err := repo.ByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i.Deleted != exp.deleted || i.State != exp.state || i.Metadata["ticket"] != exp.meta {
+			t.Errorf("Expected deleted %t, state %d and ticket %q after undo %d, got %t, %d and %q.\n",
+				exp.deleted, exp.state, exp.meta, k+1, i.Deleted, i.State, i.Metadata["ticket"])
+		}
+	}
+
+	if err := config.Undo(); !errors.Is(err, pomodoro.ErrNothingToUndo) {
+		t.Errorf("Expected error %q once every change is undone, got %q.\n",
+			pomodoro.ErrNothingToUndo, err)
+	}
+}
+
+func TestUndoThenStart(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	clock := newFakeClock(time.Date(2023, time.May, 10, 9, 0, 0, 0, time.Local))
+	config := pomodoro.NewConfig(repo, 3*time.Second, 0, 0)
+	config.Clock = clock
+	noop := func(pomodoro.Interval) {}
+
+	i, err := pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errCh := make(chan error)
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+	}()
+	clock.Tick(t, time.Second)
+	running := waitActual(t, repo, i.ID, time.Second)
+
+	// the timer stops on its next tick once paused, undoing the pause doesn't restart it
+	if err := running.Pause(config); err != nil {
+		t.Fatal(err)
+	}
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+	if err := config.Undo(); err != nil {
+		t.Fatal(err)
+	}
+
+	i, err = pomodoro.GetInterVal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i.ID != running.ID || i.State != pomodoro.StatePaused {
+		t.Fatalf("Expected interval %d to resume paused, got %d in state %d.\n", running.ID, i.ID, i.State)
+	}
+	go func() {
+		errCh <- i.Start(context.Background(), config, noop, noop, noop, noop)
+	}()
+	clock.Tick(t, time.Second)
+	clock.Tick(t, time.Second)
+	if err := <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	res := waitState(t, repo, i.ID, pomodoro.StateDone)
+	if res.ActualDuration != 3*time.Second {
+		t.Errorf("Expected actual duration %q, got %q.\n", 3*time.Second, res.ActualDuration)
+	}
+}
+
+func TestUndoLimit(t *testing.T) {
+	repo, cleanup := getRepo(t)
+	defer cleanup()
+
+	config := pomodoro.NewConfig(repo, 0, 0, 0)
+	addIntervals(t, repo, pomodoro.Interval{Category: pomodoro.CategoryPomodoro,
+		State: pomodoro.StateDone})
+
+	const changes = 40
+	for k := 1; k <= changes; k++ {
+		i, err := repo.ByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := i.SetMeta(config, "version", fmt.Sprint(k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	undone := 0
+	for config.Undo() == nil {
+		undone++
+	}
+	if undone == 0 || undone >= changes {
+		t.Fatalf("Expected the undo stack to be bounded below %d changes, undid %d.\n",
+			changes, undone)
+	}
+
+	// the oldest changes can't be undone anymore
+	i, err := repo.ByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exp := fmt.Sprint(changes - undone); i.Metadata["version"] != exp {
+		t.Errorf("Expected version %q after undoing every change kept, got %q.\n",
+			exp, i.Metadata["version"])
+	}
+}